@@ -0,0 +1,333 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+// Package aliases parses mail alias files, in the `/etc/aliases` format used
+// by sendmail and Postfix, and checks them for mistakes.
+//
+// Each alias is a name, a colon, and a list of targets separated by commas:
+//
+//	postmaster: root
+//	root:       john, jane@example.org
+//	support:    "|/usr/local/bin/ticket --queue support",
+//	            /var/spool/support/archive,
+//	            :include:/etc/mail/support-staff
+//
+// A line starting with white-space continues the previous alias, and a line
+// whose first non-white-space character is `#` is a comment.  A name or a
+// target may be enclosed in double quotes, to hold characters such as commas,
+// colons or spaces.  A target is an address (perhaps unqualified, naming a
+// local user or another alias), a command after `|`, a file with an absolute
+// path, or a file of further targets after `:include:`.  A backslash before a
+// local name, as in `\john`, stops it from being expanded as an alias.
+//
+// Parsing only checks the structure of the file; Validate checks the targets
+// against the patterns of the emailsupport package, and looks for loops.
+package aliases
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// TargetKind is the type of an alias target.
+type TargetKind int
+
+const (
+	// TargetAddress is a qualified address, `user@domain`
+	TargetAddress TargetKind = iota
+	// TargetLocal is an unqualified name: a local user or another alias
+	TargetLocal
+	// TargetCommand is a command to deliver to, from after a `|`
+	TargetCommand
+	// TargetFile is a file to append to, with an absolute path
+	TargetFile
+	// TargetInclude is a file holding more targets, from after `:include:`
+	TargetInclude
+)
+
+func (k TargetKind) String() string {
+	switch k {
+	case TargetAddress:
+		return "address"
+	case TargetLocal:
+		return "local"
+	case TargetCommand:
+		return "command"
+	case TargetFile:
+		return "file"
+	case TargetInclude:
+		return "include"
+	}
+	return fmt.Sprintf("TargetKind(%d)", int(k))
+}
+
+// Target is one of the targets of an alias.  The Value is the address or
+// local name as written (so still quoted, if it was, as in
+// `"john doe"@example.org`), or else the command or path without its prefix
+// and without any surrounding quotes.  Raw is the target as it appeared in
+// the file.
+type Target struct {
+	Kind     TargetKind
+	Value    string
+	Raw      string
+	NoExpand bool // a local name was preceded by a backslash
+	Line     int
+}
+
+// Alias is one entry of an alias file.  The Name has any quotes removed.
+type Alias struct {
+	Name    string
+	Targets []Target
+	Line    int
+}
+
+// Aliases holds the entries of an alias file, in the order of the file.
+type Aliases struct {
+	Entries []*Alias
+	byName  map[string]*Alias
+}
+
+// Lookup returns the first alias with the given name, compared without
+// regard to case as Postfix and sendmail do, or nil if there is none.
+func (a *Aliases) Lookup(name string) *Alias {
+	return a.byName[strings.ToLower(name)]
+}
+
+// SyntaxError reports a line of an alias file which could not be parsed.
+type SyntaxError struct {
+	Line   int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("aliases: line %d: %s", e.Line, e.Reason)
+}
+
+// segment is a physical line within the joined text of an entry; offset is
+// where the line starts within that text.
+type segment struct {
+	line   int
+	offset int
+}
+
+// Parse reads an alias file.  The first problem with the structure of the
+// file is returned as a *SyntaxError.
+func Parse(r io.Reader) (*Aliases, error) {
+	a := &Aliases{byName: make(map[string]*Alias)}
+	var (
+		text     strings.Builder
+		segments []segment
+		start    int
+	)
+	flush := func() error {
+		if len(segments) == 0 {
+			return nil
+		}
+		alias, err := parseEntry(text.String(), segments, start)
+		if err != nil {
+			return err
+		}
+		a.Entries = append(a.Entries, alias)
+		key := strings.ToLower(alias.Name)
+		if _, ok := a.byName[key]; !ok {
+			a.byName[key] = alias
+		}
+		text.Reset()
+		segments = segments[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(segments) == 0 {
+				return nil, &SyntaxError{Line: lineNum, Reason: "continuation line with no alias before it"}
+			}
+			// keep the line break, so that a missing comma between
+			// targets shows up as a bad target
+			text.WriteByte('\n')
+		} else {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			start = lineNum
+		}
+		segments = append(segments, segment{line: lineNum, offset: text.Len()})
+		text.WriteString(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ParseFile reads an alias file by name; see Parse.
+func ParseFile(filename string) (*Aliases, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	a, err := Parse(fh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return a, nil
+}
+
+// lineAt returns the line of the file holding the offset in the entry text.
+func lineAt(segments []segment, offset int) int {
+	line := segments[0].line
+	for _, s := range segments {
+		if s.offset > offset {
+			break
+		}
+		line = s.line
+	}
+	return line
+}
+
+func parseEntry(text string, segments []segment, lineNum int) (*Alias, error) {
+	alias := &Alias{Line: lineNum}
+	rest := text
+	if strings.HasPrefix(text, `"`) {
+		end := closingQuote(text)
+		if end < 0 {
+			return nil, &SyntaxError{Line: lineNum, Reason: "unterminated quoted alias name"}
+		}
+		alias.Name = unquote(text[:end+1])
+		rest = strings.TrimLeft(text[end+1:], " \t")
+		if !strings.HasPrefix(rest, ":") {
+			return nil, &SyntaxError{Line: lineNum, Reason: "expected ':' after the quoted alias name"}
+		}
+	} else {
+		colon := strings.IndexByte(text, ':')
+		if colon < 0 {
+			return nil, &SyntaxError{Line: lineNum, Reason: "expected `name: targets`, found no colon"}
+		}
+		alias.Name = strings.TrimRight(text[:colon], " \t")
+		rest = text[colon:]
+		if strings.ContainsAny(alias.Name, " \t") {
+			return nil, &SyntaxError{Line: lineNum, Reason: fmt.Sprintf("alias name %q contains white-space, so must be quoted", alias.Name)}
+		}
+	}
+	if alias.Name == "" {
+		return nil, &SyntaxError{Line: lineNum, Reason: "empty alias name"}
+	}
+	base := len(text) - len(rest) + 1 // after the colon
+	rest = rest[1:]
+
+	inQuotes := false
+	itemStart := 0
+	add := func(end int) {
+		raw := strings.TrimSpace(rest[itemStart:end])
+		if raw != "" {
+			offset := base + itemStart + strings.Index(rest[itemStart:end], raw)
+			alias.Targets = append(alias.Targets, newTarget(raw, lineAt(segments, offset)))
+		}
+		itemStart = end + 1
+	}
+	for i := 0; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			add(i)
+		}
+	}
+	if inQuotes {
+		return nil, &SyntaxError{Line: lineNum, Reason: "unterminated quoted target"}
+	}
+	add(len(rest))
+	return alias, nil
+}
+
+// closingQuote returns the index of the double quote closing the string
+// which starts at the start of s, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote removes the double quotes, and the backslashes quoting the
+// characters within, from a quoted string.
+func unquote(quoted string) string {
+	var b strings.Builder
+	inner := quoted[1 : len(quoted)-1]
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		b.WriteByte(inner[i])
+	}
+	return b.String()
+}
+
+func newTarget(raw string, line int) Target {
+	t := Target{Raw: raw, Line: line}
+	value := raw
+	// a target which is entirely quoted may be a command, file or include
+	// holding commas or spaces; or a quoted local name
+	if strings.HasPrefix(value, `"`) && closingQuote(value) == len(value)-1 {
+		if inner := unquote(value); isSpecial(inner) {
+			value = inner
+		}
+	}
+	switch {
+	case strings.HasPrefix(value, "|"):
+		t.Kind, t.Value = TargetCommand, strings.TrimSpace(value[1:])
+	case strings.HasPrefix(value, "/"):
+		t.Kind, t.Value = TargetFile, value
+	case len(value) >= 9 && strings.EqualFold(value[:9], ":include:"):
+		t.Kind, t.Value = TargetInclude, strings.TrimSpace(value[9:])
+	default:
+		if strings.HasPrefix(value, `\`) {
+			t.NoExpand = true
+			value = value[1:]
+		}
+		t.Kind, t.Value = TargetLocal, value
+		if hasUnquotedAt(value) {
+			t.Kind = TargetAddress
+		}
+	}
+	return t
+}
+
+func isSpecial(value string) bool {
+	return strings.HasPrefix(value, "|") || strings.HasPrefix(value, "/") ||
+		len(value) >= 9 && strings.EqualFold(value[:9], ":include:")
+}
+
+func hasUnquotedAt(s string) bool {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '@' && !inQuotes:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package aliases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philpennock/emailsupport"
+)
+
+const sample = `# sample aliases
+postmaster:	root
+root: john, \root,
+	jane@example.org
+# a comment within an entry
+	, "john doe"@example.org
+"help desk": "|/usr/local/bin/ticket --queue=help, urgent",
+  "/var/spool/help desk/archive" , :INCLUDE: /etc/mail/help
+MAILER-DAEMON: postmaster
+`
+
+func mustParse(t *testing.T, text string) *Aliases {
+	t.Helper()
+	a, err := Parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func describeTargets(targets []Target) string {
+	var parts []string
+	for _, t := range targets {
+		s := fmt.Sprintf("%s:%s@%d", t.Kind, t.Value, t.Line)
+		if t.NoExpand {
+			s = "\\" + s
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestParse(t *testing.T) {
+	a := mustParse(t, sample)
+	for _, tc := range []struct {
+		name    string
+		line    int
+		targets string
+	}{
+		{"postmaster", 2, "local:root@2"},
+		{"root", 3, `local:john@3 | \local:root@3 | address:jane@example.org@4 | address:"john doe"@example.org@6`},
+		{"help desk", 7, "command:/usr/local/bin/ticket --queue=help, urgent@7 | file:/var/spool/help desk/archive@8 | include:/etc/mail/help@8"},
+		{"mailer-daemon", 9, "local:postmaster@9"},
+	} {
+		alias := a.Lookup(tc.name)
+		if alias == nil {
+			t.Errorf("no alias %q", tc.name)
+			continue
+		}
+		if alias.Line != tc.line {
+			t.Errorf("%s: at line %d, expected %d", tc.name, alias.Line, tc.line)
+		}
+		if got := describeTargets(alias.Targets); got != tc.targets {
+			t.Errorf("%s: targets\n got: %s\nwant: %s", tc.name, got, tc.targets)
+		}
+	}
+	if len(a.Entries) != 4 {
+		t.Errorf("got %d entries, expected 4", len(a.Entries))
+	}
+	if a.Entries[3].Name != "MAILER-DAEMON" {
+		t.Errorf("name not kept as written: %q", a.Entries[3].Name)
+	}
+	if problems := Validate(a); len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for text, line := range map[string]int{
+		"  root\n":                    1,
+		"# c\n\nroot\n":               3,
+		"a: b\nroot john\n":           2,
+		"\"root: john\n":              1,
+		"\"root\" john\n":             1,
+		"root: \"|prog\n  more\nx: y": 1,
+		"help desk: john\n":           1,
+		": john\n":                    1,
+	} {
+		_, err := Parse(strings.NewReader(text))
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: expected a SyntaxError, got %v", text, err)
+			continue
+		}
+		if se.Line != line {
+			t.Errorf("%q: error at line %d, expected %d: %v", text, se.Line, line, se)
+		}
+	}
+}
+
+func describeProblems(problems []Problem) string {
+	var parts []string
+	for _, p := range problems {
+		parts = append(parts, fmt.Sprintf("%d %s %s", p.Line, p.Alias, p.Target))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestValidate(t *testing.T) {
+	a := mustParse(t, `a: john..doe, jane@example, ok@example.org
+b:
+c: john doe
+	jane, "|", :include:relative, /tmp/fine
+a: again
+`)
+	problems := Validate(a)
+	if got, want := describeProblems(problems), "1 a john..doe | 1 a jane@example | 2 b  | 3 c john doe\n\tjane | 4 c \"|\" | 4 c :include:relative | 5 a "; got != want {
+		t.Errorf("problems\n got: %q\nwant: %q", got, want)
+	}
+	for _, p := range problems {
+		if p.Reason == "" || !strings.HasPrefix(p.String(), fmt.Sprintf("line %d: alias ", p.Line)) {
+			t.Errorf("badly described: %q", p.String())
+		}
+	}
+}
+
+func TestValidateGrammar(t *testing.T) {
+	a := mustParse(t, "a: \"\x01\"@example.org\n")
+	if problems := (&Validator{Grammar: emailsupport.RFC2822()}).Validate(a); len(problems) != 0 {
+		t.Errorf("RFC2822 rejected a control character: %v", problems)
+	}
+	if problems := (&Validator{Grammar: emailsupport.RFC5321()}).Validate(a); len(problems) != 1 {
+		t.Errorf("RFC5321 accepted a control character")
+	}
+}
+
+func TestLoops(t *testing.T) {
+	a := mustParse(t, `self: self, other
+x: y
+y: "z"
+z: X
+p: q@Example.ORG
+q: \p, r
+r: p@example.org
+s: s@example.net
+`)
+	for _, tc := range []struct {
+		v      *Validator
+		expect string
+	}{
+		{&Validator{}, "x -> y -> z -> x"},
+		{&Validator{LocalDomains: []string{"example.org"}}, "x -> y -> z -> x; p -> q -> r -> p"},
+		{&Validator{LocalDomains: []string{"example.org", "example.net"}}, "x -> y -> z -> x; p -> q -> r -> p"},
+	} {
+		var loops []string
+		for _, loop := range tc.v.Loops(a) {
+			loops = append(loops, strings.Join(loop, " -> "))
+		}
+		if got := strings.Join(loops, "; "); got != tc.expect {
+			t.Errorf("with local domains %v, got loops %q, expected %q", tc.v.LocalDomains, got, tc.expect)
+		}
+	}
+	problems := Validate(a)
+	if len(problems) != 1 || problems[0].Line != 2 || !strings.Contains(problems[0].Reason, "x -> y -> z -> x") {
+		t.Errorf("loop not reported: %v", problems)
+	}
+}
@@ -0,0 +1,266 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package aliases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/philpennock/emailsupport"
+)
+
+// Problem is something wrong with an alias file which does not stop it from
+// being parsed.  Target is the raw target, if the problem is with one.
+type Problem struct {
+	Line   int
+	Alias  string
+	Target string
+	Reason string
+}
+
+func (p Problem) String() string {
+	if p.Target != "" {
+		return fmt.Sprintf("line %d: alias %q: target %q: %s", p.Line, p.Alias, p.Target, p.Reason)
+	}
+	return fmt.Sprintf("line %d: alias %q: %s", p.Line, p.Alias, p.Reason)
+}
+
+// Validator checks parsed alias files.  The zero value is ready to use, and
+// checks local parts with the emailsupport.DefaultGrammar unless Grammar is
+// set.  An address in one of the LocalDomains is expanded as an alias, just
+// as an unqualified name is, as Postfix does for the domains in
+// $mydestination.
+type Validator struct {
+	Grammar      *emailsupport.Grammar
+	LocalDomains []string
+}
+
+// Validate checks the aliases using a zero Validator.
+func Validate(a *Aliases) []Problem {
+	return (&Validator{}).Validate(a)
+}
+
+// Validate checks that each address target is matched by
+// `EmailAddressOrUnqualified` (as an address if it has an `@`, and as a local
+// part if not), that each command and included file is named, and that no
+// alias is defined twice or has no targets.  Then it looks for loops, where
+// the expansion of an alias leads back to it; an alias which lists itself is
+// not a loop, but delivers to the local user of that name.  The problems are
+// in the order of the file, with the loops at the end.
+func (v *Validator) Validate(a *Aliases) []Problem {
+	var problems []Problem
+	for _, alias := range a.Entries {
+		if first := a.Lookup(alias.Name); first != alias {
+			problems = append(problems, Problem{Line: alias.Line, Alias: alias.Name,
+				Reason: fmt.Sprintf("duplicate of the alias at line %d", first.Line)})
+		}
+		if len(alias.Targets) == 0 {
+			problems = append(problems, Problem{Line: alias.Line, Alias: alias.Name, Reason: "no targets"})
+		}
+		for _, t := range alias.Targets {
+			if reason := v.checkTarget(t); reason != "" {
+				problems = append(problems, Problem{Line: t.Line, Alias: alias.Name, Target: t.Raw, Reason: reason})
+			}
+		}
+	}
+	for _, loop := range v.Loops(a) {
+		first := a.Lookup(loop[0])
+		problems = append(problems, Problem{Line: first.Line, Alias: first.Name,
+			Reason: "alias loop: " + strings.Join(loop, " -> ")})
+	}
+	return problems
+}
+
+func (v *Validator) grammar() *emailsupport.Grammar {
+	if v.Grammar != nil {
+		return v.Grammar
+	}
+	return emailsupport.DefaultGrammar()
+}
+
+// checkTarget returns why the target is bad, or "" if it is fine.
+func (v *Validator) checkTarget(t Target) string {
+	var err error
+	switch t.Kind {
+	case TargetAddress:
+		err = v.grammar().ValidateEmailAddress(t.Value)
+	case TargetLocal:
+		err = v.grammar().ValidateEmailLHS(t.Value)
+	case TargetCommand:
+		if t.Value == "" {
+			return "empty command"
+		}
+	case TargetInclude:
+		if !strings.HasPrefix(t.Value, "/") {
+			return "the :include: file must have an absolute path"
+		}
+	}
+	if err == nil {
+		return ""
+	}
+	var pe *emailsupport.ParseError
+	if errors.As(err, &pe) {
+		return fmt.Sprintf("not a valid %s: bad %s at offset %d: %s", t.Kind, pe.Production, pe.Offset, pe.Reason)
+	}
+	return err.Error()
+}
+
+// expandsTo returns the alias which the target names, if any.
+func (v *Validator) expandsTo(a *Aliases, t Target) *Alias {
+	if t.NoExpand {
+		return nil
+	}
+	switch t.Kind {
+	case TargetLocal:
+		if v.grammar().ValidateEmailLHS(t.Value) != nil {
+			return nil
+		}
+		return a.Lookup(unquoteLocalPart(t.Value))
+	case TargetAddress:
+		addr, err := v.grammar().ParseAddress(t.Value)
+		if err != nil {
+			return nil
+		}
+		for _, domain := range v.LocalDomains {
+			if strings.EqualFold(addr.Domain, domain) {
+				return a.Lookup(addr.LocalPart)
+			}
+		}
+	}
+	return nil
+}
+
+func unquoteLocalPart(lhs string) string {
+	if strings.HasPrefix(lhs, `"`) {
+		return unquote(lhs)
+	}
+	return lhs
+}
+
+// Loops returns each loop in the expansion of the aliases, as the names of
+// the aliases in one path around it, starting and ending with the alias of
+// the loop which is first in the file.  Where several loops are tangled
+// together, only one path is given.
+func (v *Validator) Loops(a *Aliases) [][]string {
+	index := make(map[*Alias]int, len(a.Entries))
+	for i, alias := range a.Entries {
+		if a.Lookup(alias.Name) == alias {
+			index[alias] = i
+		}
+	}
+	// edges are to the first definition of a name, as Lookup finds
+	edges := make(map[int][]int, len(index))
+	for alias, i := range index {
+		for _, t := range alias.Targets {
+			if to := v.expandsTo(a, t); to != nil && to != alias {
+				edges[i] = append(edges[i], index[to])
+			}
+		}
+	}
+
+	var loops [][]string
+	for _, component := range stronglyConnected(len(a.Entries), edges) {
+		if len(component) < 2 {
+			continue
+		}
+		var names []string
+		for _, i := range cycleThrough(component, edges) {
+			names = append(names, a.Entries[i].Name)
+		}
+		loops = append(loops, names)
+	}
+	return loops
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// (Tarjan's algorithm), each sorted, in the order of their first node.
+func stronglyConnected(n int, edges map[int][]int) [][]int {
+	var (
+		counter    int
+		order      = make([]int, n) // 0 for unvisited, else 1 + visit order
+		low        = make([]int, n)
+		onStack    = make([]bool, n)
+		stack      []int
+		components [][]int
+	)
+	var visit func(i int)
+	visit = func(i int) {
+		counter++
+		order[i], low[i] = counter, counter
+		stack = append(stack, i)
+		onStack[i] = true
+		for _, j := range edges[i] {
+			switch {
+			case order[j] == 0:
+				visit(j)
+				if low[j] < low[i] {
+					low[i] = low[j]
+				}
+			case onStack[j] && order[j] < low[i]:
+				low[i] = order[j]
+			}
+		}
+		if low[i] != order[i] {
+			return
+		}
+		var component []int
+		for {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[j] = false
+			component = append(component, j)
+			if j == i {
+				break
+			}
+		}
+		sort.Ints(component)
+		components = append(components, component)
+	}
+	for i := 0; i < n; i++ {
+		if order[i] == 0 {
+			visit(i)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// cycleThrough returns a shortest path from the first node of a strongly
+// connected component back to itself, staying within the component.
+func cycleThrough(component []int, edges map[int][]int) []int {
+	in := make(map[int]bool, len(component))
+	for _, i := range component {
+		in[i] = true
+	}
+	start := component[0]
+	from := map[int]int{}
+	queue := []int{start}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, j := range edges[i] {
+			if !in[j] {
+				continue
+			}
+			if j == start {
+				path := []int{start}
+				for k := i; k != start; k = from[k] {
+					path = append(path, k)
+				}
+				path = append(path, start)
+				// reverse all but the ends, which are both start
+				for l, r := 1, len(path)-2; l < r; l, r = l+1, r-1 {
+					path[l], path[r] = path[r], path[l]
+				}
+				return path
+			}
+			if _, seen := from[j]; !seen && j != start {
+				from[j] = i
+				queue = append(queue, j)
+			}
+		}
+	}
+	return nil // not reached for a component of two or more
+}
@@ -0,0 +1,97 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Canonical returns the address in a canonical form, so that two addresses
+// for the same mailbox have the same canonical form:
+//
+//   - the local part is quoted if and only if it needs to be
+//   - a domain name is lower-cased
+//   - an IPv6 address-literal has the tag written as "IPv6" and the address
+//     lower-cased and compressed per RFC5952
+//
+// The local part is not case-folded, because RFC5321 says that it is
+// case-sensitive, even though few systems treat it that way; see Equal.
+func (a Address) Canonical() Address {
+	a.QuotedLocalPart = !isDotString(a.LocalPart)
+	if a.AddressLiteral {
+		a.Domain = canonicalAddressLiteral(a.Domain)
+	} else {
+		a.Domain = strings.ToLower(a.Domain)
+	}
+	return a
+}
+
+// Canonicalize parses an address and returns the canonical form, as a string.
+func Canonicalize(address string) (string, error) {
+	a, err := ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return a.Canonical().String(), nil
+}
+
+// Equal reports whether two addresses refer to the same mailbox, comparing
+// their canonical forms.  If foldLocalPart is true then the local parts are
+// compared case-insensitively, as most (but not all) mail systems do.
+func (a Address) Equal(b Address, foldLocalPart bool) bool {
+	ca, cb := a.Canonical(), b.Canonical()
+	if ca.Domain != cb.Domain {
+		return false
+	}
+	if foldLocalPart {
+		return strings.EqualFold(ca.LocalPart, cb.LocalPart)
+	}
+	return ca.LocalPart == cb.LocalPart
+}
+
+// Equal parses two addresses and reports whether they refer to the same
+// mailbox; see Address.Equal.
+func Equal(x, y string, foldLocalPart bool) (bool, error) {
+	a, err := ParseAddress(x)
+	if err != nil {
+		return false, err
+	}
+	b, err := ParseAddress(y)
+	if err != nil {
+		return false, err
+	}
+	return a.Equal(b, foldLocalPart), nil
+}
+
+// isDotString reports whether s can be used as a local part without quoting
+func isDotString(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || strings.Contains(s, "..") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '.' && !isAText(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalAddressLiteral normalises IP address-literals; anything else (a
+// General-address-literal) is returned unchanged.
+func canonicalAddressLiteral(literal string) string {
+	if len(literal) < 2 || literal[0] != '[' || literal[len(literal)-1] != ']' {
+		return literal
+	}
+	content := literal[1 : len(literal)-1]
+	if len(content) >= 5 && strings.EqualFold(content[:5], "IPv6:") {
+		if ip, err := netip.ParseAddr(content[5:]); err == nil {
+			return "[IPv6:" + ip.String() + "]"
+		}
+		return literal
+	}
+	if ip, err := netip.ParseAddr(content); err == nil && ip.Is4() {
+		return "[" + ip.String() + "]"
+	}
+	return literal
+}
@@ -0,0 +1,79 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for _, item := range []struct {
+		in, out string
+	}{
+		{`john@example.org`, `john@example.org`},
+		{`john@Example.ORG`, `john@example.org`},
+		{`John@example.org`, `John@example.org`},
+		{`"john"@Example.ORG`, `john@example.org`},
+		{`"john.doe"@example.org`, `john.doe@example.org`},
+		{`"j\ohn"@example.org`, `john@example.org`},
+		{`"john doe"@example.org`, `"john doe"@example.org`},
+		{`"john..doe"@example.org`, `"john..doe"@example.org`},
+		{`".john"@example.org`, `".john"@example.org`},
+		{`""@example.org`, `""@example.org`},
+		{`"a\"b"@example.org`, `"a\"b"@example.org`},
+		{`"a@b"@example.org`, `"a@b"@example.org`},
+		{`john@[IPv6:2001:DB8::42]`, `john@[IPv6:2001:db8::42]`},
+		{`john@[ipv6:2001:db8:0:0:0:0:0:42]`, `john@[IPv6:2001:db8::42]`},
+		{`john@[IPv6:2001:0db8::0042]`, `john@[IPv6:2001:db8::42]`},
+		{`john@[IPv6:::FFFF:192.0.2.1]`, `john@[IPv6:::ffff:192.0.2.1]`},
+		{`john@[192.0.2.1]`, `john@[192.0.2.1]`},
+	} {
+		got, err := Canonicalize(item.in)
+		if err != nil {
+			t.Errorf("Canonicalize(%q) failed: %v", item.in, err)
+			continue
+		}
+		if got != item.out {
+			t.Errorf("Canonicalize(%q) gave %q, expected %q", item.in, got, item.out)
+		}
+		if !EmailAddress.MatchString(got) {
+			t.Errorf("Canonicalize(%q) gave %q, which is not an EmailAddress", item.in, got)
+		}
+	}
+	if _, err := Canonicalize("john@"); err == nil {
+		t.Errorf("Canonicalize accepted a bad address")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, item := range []struct {
+		x, y     string
+		folded   bool
+		unfolded bool
+	}{
+		{`"john"@Example.ORG`, `john@example.org`, true, true},
+		{`John@example.org`, `john@EXAMPLE.org`, true, false},
+		{`john@example.org`, `jane@example.org`, false, false},
+		{`john@example.org`, `john@example.com`, false, false},
+		{`john@[IPv6:2001:DB8::42]`, `john@[ipv6:2001:db8:0::42]`, true, true},
+		{`john@[192.0.2.1]`, `john@[IPv6:::ffff:192.0.2.1]`, false, false},
+	} {
+		for _, fold := range []bool{true, false} {
+			expect := item.unfolded
+			if fold {
+				expect = item.folded
+			}
+			got, err := Equal(item.x, item.y, fold)
+			if err != nil {
+				t.Errorf("Equal(%q, %q, %v) failed: %v", item.x, item.y, fold, err)
+				continue
+			}
+			if got != expect {
+				t.Errorf("Equal(%q, %q, %v) gave %v, expected %v", item.x, item.y, fold, got, expect)
+			}
+		}
+	}
+	if _, err := Equal("john@example.org", "john", false); err == nil {
+		t.Errorf("Equal accepted a bad address")
+	}
+}
@@ -0,0 +1,92 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"regexp"
+)
+
+// The Capturing forms are the exception to the rule that no pattern here has
+// capturing groups, for pulling the parts out of (say) a log line without
+// rebuilding the patterns by hand.  They have named groups:
+//
+//   - lhs: the local part, as written (so still quoted, if it was)
+//   - domain: the domain, including the brackets of an address-literal
+//   - ipv4literal: the address within an IPv4 address-literal
+//   - ipv6literal: the address within an IPv6 address-literal, without the tag
+//
+// Use SubexpIndex, or CaptureAddress, rather than counting groups.  Because
+// the group names are fixed, a Capturing pattern can only be embedded once in
+// a larger pattern.  The `Txt` forms without the suffix are unchanged.
+
+var TxtEmailDomainCapturing string = buildEmailDomain(txtLetDig, txtLDH, txtLetDig, false, true)
+
+var TxtEmailAddressCapturing = defaultGrammar.txtEmailAddressCapturing
+
+var TxtEmailAddressOrUnqualifiedCapturing = defaultGrammar.txtEmailAddressOrUnqualifiedCapturing
+
+var (
+	EmailDomainCapturingUnanchored               = regexp.MustCompile(TxtEmailDomainCapturing)
+	EmailDomainCapturing                         = regexp.MustCompile(start + TxtEmailDomainCapturing + end)
+	EmailAddressCapturingUnanchored              = defaultGrammar.emailAddressCapturingUnanchored
+	EmailAddressCapturing                        = defaultGrammar.emailAddressCapturing
+	EmailAddressOrUnqualifiedCapturingUnanchored = defaultGrammar.emailAddressOrUnqualifiedCapturingUnanchored
+	EmailAddressOrUnqualifiedCapturing           = defaultGrammar.emailAddressOrUnqualifiedCapturing
+)
+
+// CapturedAddress holds the named groups of a match against one of the
+// Capturing patterns; a group which did not take part in the match is empty.
+type CapturedAddress struct {
+	LHS         string
+	Domain      string
+	IPv4Literal string
+	IPv6Literal string
+}
+
+// CaptureAddress matches text against a Capturing pattern (or any pattern with
+// the same group names) and returns the groups from the leftmost match.
+func CaptureAddress(re *regexp.Regexp, text string) (CapturedAddress, bool) {
+	submatch := re.FindStringSubmatch(text)
+	if submatch == nil {
+		return CapturedAddress{}, false
+	}
+	return CapturedAddressFromSubmatch(re, submatch), true
+}
+
+// CapturedAddressFromSubmatch takes the result of FindStringSubmatch, or one
+// element of the result of FindAllStringSubmatch, for the pattern re.
+func CapturedAddressFromSubmatch(re *regexp.Regexp, submatch []string) CapturedAddress {
+	var c CapturedAddress
+	for i, name := range re.SubexpNames() {
+		if i >= len(submatch) {
+			break
+		}
+		switch name {
+		case "lhs":
+			c.LHS = submatch[i]
+		case "domain":
+			c.Domain = submatch[i]
+		case "ipv4literal":
+			c.IPv4Literal = submatch[i]
+		case "ipv6literal":
+			c.IPv6Literal = submatch[i]
+		}
+	}
+	return c
+}
+
+// Map returns the groups keyed by their names, omitting any which are empty.
+func (c CapturedAddress) Map() map[string]string {
+	m := make(map[string]string, 4)
+	for name, value := range map[string]string{
+		"lhs":         c.LHS,
+		"domain":      c.Domain,
+		"ipv4literal": c.IPv4Literal,
+		"ipv6literal": c.IPv6Literal,
+	} {
+		if value != "" {
+			m[name] = value
+		}
+	}
+	return m
+}
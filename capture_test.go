@@ -0,0 +1,72 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapturingAgreesWithPlain(t *testing.T) {
+	for _, pair := range []struct {
+		label            string
+		plain, capturing interface{ MatchString(string) bool }
+		list             []boolPatternMatch
+	}{
+		{"EmailDomainCapturing", EmailDomain, EmailDomainCapturing, testEmailDomain},
+		{"EmailAddressCapturing", EmailAddress, EmailAddressCapturing, testEmailAddress},
+		{"EmailAddressOrUnqualifiedCapturing", EmailAddressOrUnqualified, EmailAddressOrUnqualifiedCapturing, testEmailAddressOrUnqualified},
+		{"RFC2822.EmailAddressCapturing", RFC2822().EmailAddress(), RFC2822().EmailAddressCapturing(), testEmailAddress},
+		{"RFC5321.EmailAddressCapturing", RFC5321().EmailAddress(), RFC5321().EmailAddressCapturing(), testEmailAddress},
+	} {
+		for _, item := range pair.list {
+			if pair.plain.MatchString(item.text) != pair.capturing.MatchString(item.text) {
+				t.Errorf("%s disagrees with the plain pattern on %q", pair.label, item.text)
+			}
+		}
+	}
+}
+
+func TestCaptureAddress(t *testing.T) {
+	for _, item := range []struct {
+		text string
+		want CapturedAddress
+	}{
+		{`john@example.org`, CapturedAddress{LHS: `john`, Domain: `example.org`}},
+		{`"john doe"@example.org`, CapturedAddress{LHS: `"john doe"`, Domain: `example.org`}},
+		{`john@[192.0.2.1]`, CapturedAddress{LHS: `john`, Domain: `[192.0.2.1]`, IPv4Literal: `192.0.2.1`}},
+		{`john@[IPv6:2001:db8::42]`, CapturedAddress{LHS: `john`, Domain: `[IPv6:2001:db8::42]`, IPv6Literal: `2001:db8::42`}},
+	} {
+		got, ok := CaptureAddress(EmailAddressCapturing, item.text)
+		if !ok || got != item.want {
+			t.Errorf("CaptureAddress(%q) gave %+v, %v; expected %+v", item.text, got, ok, item.want)
+		}
+	}
+	if _, ok := CaptureAddress(EmailAddressCapturing, `john@`); ok {
+		t.Errorf("CaptureAddress matched a bad address")
+	}
+
+	got, ok := CaptureAddress(EmailAddressOrUnqualifiedCapturing, `postmaster`)
+	if !ok || got != (CapturedAddress{LHS: `postmaster`}) {
+		t.Errorf("unqualified CaptureAddress gave %+v, %v", got, ok)
+	}
+	got, ok = CaptureAddress(EmailDomainCapturing, `[IPv6:::1]`)
+	if !ok || got != (CapturedAddress{Domain: `[IPv6:::1]`, IPv6Literal: `::1`}) {
+		t.Errorf("domain CaptureAddress gave %+v, %v", got, ok)
+	}
+}
+
+func TestCaptureFromLogLine(t *testing.T) {
+	line := `2026-10-17 12:00:00 1abcde-000001-AB <= sender@example.org H=mx.example.net [192.0.2.1] for rcpt@[IPv6:2001:db8::25]`
+	var found []map[string]string
+	for _, submatch := range EmailAddressCapturingUnanchored.FindAllStringSubmatch(line, -1) {
+		found = append(found, CapturedAddressFromSubmatch(EmailAddressCapturingUnanchored, submatch).Map())
+	}
+	want := []map[string]string{
+		{"lhs": "sender", "domain": "example.org"},
+		{"lhs": "rcpt", "domain": "[IPv6:2001:db8::25]", "ipv6literal": "2001:db8::25"},
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("extracting from log line gave %v, expected %v", found, want)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+)
+
+// Finding addresses in free text relies on the unanchored pattern and on the
+// leftmost-first matching of Go regexps; a full stop or comma after an
+// address in prose is not taken into the domain, because a domain can not end
+// with a dot and the match backs off to the longest valid domain.  That leaves
+// two problems at the edges of a match:
+//
+//   - Some punctuation used to wrap words in prose, such as `'` and `*`, is
+//     valid atext, so `'john@example.org'` matches as `'john@example.org`.  If
+//     a match starts with such a character and the same (or the matching
+//     closing) character follows the match, then both are dropped, so long as
+//     what is left is still an address.
+//   - A match can be the tail of some longer token which is not an address,
+//     such as `john..doe@example.org` matching as `doe@example.org`.  A match
+//     which is preceded by atext, a dot or an `@`, or followed by an `@`, a
+//     hyphen or a letter or digit, is skipped.
+//
+// A URL such as `http://user@example.org/path` also matches, from after the
+// colon, as `//user@example.org`, because `/` is atext.  Slashes after the
+// `scheme:` of a URL are dropped, and if what is left is not an address then
+// the match is skipped.
+
+// wrappers maps the atext which might wrap an address in prose to the
+// character which would close it.
+var wrappers = map[byte]byte{
+	'\'': '\'',
+	'`':  '`',
+	'{':  '}',
+	'*':  '*',
+	'_':  '_',
+	'~':  '~',
+	'|':  '|',
+}
+
+// isAText reports whether c is atext, as in the package.
+func isAText(c byte) bool {
+	return isLetDig(c) || strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0
+}
+
+func isLetDig(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// trimWrapping returns the start of the match after dropping any wrapping
+// punctuation, and the end of that punctuation after the match.
+func trimWrapping(text string, start, end int, valid func(string) bool) (int, int) {
+	after := end
+	for start < end-1 {
+		closer, ok := wrappers[text[start]]
+		if !ok || after >= len(text) || text[after] != closer || !valid(text[start+1:end]) {
+			break
+		}
+		start++
+		after++
+	}
+	return start, after
+}
+
+// trimURLSlashes returns the start of the match after any slashes following
+// the `scheme:` of a URL, and false if what is left is not an address.
+func trimURLSlashes(text string, start, end int, valid func(string) bool) (int, bool) {
+	if start == 0 || text[start-1] != ':' || text[start] != '/' {
+		return start, true
+	}
+	for start < end && text[start] == '/' {
+		start++
+	}
+	return start, valid(text[start:end])
+}
+
+// atAddressBoundary reports whether the text before start and from after
+// leaves the match, with any wrapping, standing alone.
+func atAddressBoundary(text string, start, after int) bool {
+	if start > 0 {
+		if c := text[start-1]; isAText(c) || c == '.' || c == '@' {
+			return false
+		}
+	}
+	if after < len(text) {
+		if c := text[after]; c == '@' || c == '-' || isLetDig(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractFrom finds the addresses in the text of the item and checks each
+// against the pattern.  The Column of each result is in bytes, from 1.
+func (c *checker) extractFrom(item inputItem, file string) []*result {
+	var results []*result
+	for _, loc := range c.grammar.EmailAddressUnanchored().FindAllStringIndex(item.text, -1) {
+		end := loc[1]
+		start, ok := trimURLSlashes(item.text, loc[0], end, c.grammar.EmailAddress().MatchString)
+		if !ok {
+			continue
+		}
+		start, after := trimWrapping(item.text, start, end, c.grammar.EmailAddress().MatchString)
+		if !atAddressBoundary(item.text, loc[0], after) {
+			continue
+		}
+		r := c.check(item.text[start:end])
+		r.File = file
+		r.Line = item.line
+		r.Column = start + 1
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philpennock/emailsupport"
+)
+
+func extractAll(t *testing.T, patternName, text string) []*result {
+	t.Helper()
+	p, ok := patternByName(patternName)
+	if !ok {
+		t.Fatalf("no pattern %q", patternName)
+	}
+	c := NewChecker(nil, p, emailsupport.RFC5321())
+	c.extract = true
+	return c.checkItem(inputItem{text: text, line: 7}, "prose")
+}
+
+func TestExtract(t *testing.T) {
+	for text, expect := range map[string]string{
+		"nothing to see here":                                  "",
+		"Mail john@example.org.":                               "john@example.org:6",
+		"john@example.org, jane@example.org; bob":              "john@example.org:1 jane@example.org:19",
+		"Try 'john@example.org' or `jane@example.org`":         "john@example.org:6 jane@example.org:28",
+		"**john@example.org** and _jane@example.org_":          "john@example.org:3 jane@example.org:27",
+		"{john@[192.0.2.1]} (jane@[IPv6:::1])":                 "john@[192.0.2.1]:2 jane@[IPv6:::1]:21",
+		"<john@example.org>":                                   "john@example.org:2",
+		`He said "john@example.org" twice`:                     "john@example.org:10",
+		`Mail "john doe"@example.org today`:                    `"john doe"@example.org:6`,
+		"'john'@example.org":                                   "'john'@example.org:1",
+		"*bob*@example.org":                                    "*bob*@example.org:1",
+		"john..doe@example.org":                                "",
+		"a@john@example.org":                                   "",
+		`x"john doe"@example.org`:                              "",
+		"john@[192.0.2.1]x":                                    "",
+		"john@example.org-":                                    "",
+		"john@example.org-based.example":                       "john@example.org-based.example:1",
+		"See http://user@example.org/path":                     "user@example.org:12",
+		"ssh://git@example.org:22/repo and mailto:a@b.example": "git@example.org:7 a@b.example:42",
+		"file:///@example.org":                                 "",
+	} {
+		var got []string
+		for _, r := range extractAll(t, "address", text) {
+			if !r.Valid || r.File != "prose" || r.Line != 7 {
+				t.Errorf("%q: bad result %+v", text, r)
+			}
+			got = append(got, fmt.Sprintf("%s:%d", r.Input, r.Column))
+		}
+		if strings.Join(got, " ") != expect {
+			t.Errorf("extracting from %q gave %q, expected %q", text, strings.Join(got, " "), expect)
+		}
+	}
+}
+
+func TestExtractStrict(t *testing.T) {
+	long := strings.Repeat("x", emailsupport.MaxLocalPartLength+1) + "@example.org"
+	results := extractAll(t, "strict", "fine@example.org and "+long)
+	if len(results) != 2 {
+		t.Fatalf("found %d addresses, expected 2", len(results))
+	}
+	if !results[0].Valid {
+		t.Errorf("short address failed: %+v", results[0])
+	}
+	if r := results[1]; r.Valid || r.Input != long || r.Column != 22 || !strings.Contains(r.Reason, "local-part") {
+		t.Errorf("long address not rejected: %+v", r)
+	}
+}
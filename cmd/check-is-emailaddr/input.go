@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// inputItem is one thing to check, from line of the input.  If the input
+// itself is broken, then problem says how, and the item is reported as a
+// failure without being checked.  A local item is a bare local part, such as
+// the target of an alias, which is checked as one whatever the pattern.
+type inputItem struct {
+	text    string
+	line    int
+	problem string
+	local   bool
+}
+
+// itemSource yields the items from some input; err is for a problem which
+// stopped the reading, and not for problems with individual items.
+type itemSource interface {
+	next() (inputItem, bool)
+	err() error
+}
+
+type inputOptions struct {
+	column string
+	field  string
+}
+
+type inputFormat struct {
+	name        string
+	description string
+	open        func(in io.Reader, opts inputOptions) (itemSource, error)
+}
+
+var inputFormats = []inputFormat{
+	{"lines", "one item per line (no comments, no exceptions except completely blank lines)", openLines},
+	{"comments", "one item per line, with # or ; starting a comment at the start of a line or after white-space", openComments},
+	{"csv", "CSV, checking the -column given by number, or by name from a header line", openCSV},
+	{"aliases", "alias files, checking each target of `name: target, target`, with bare names as local parts; commands, files and :include: are skipped", openAliases},
+	{"jsonl", "JSON, one object per line, checking the string (or strings) at the dotted -field path", openJSONL},
+}
+
+func inputFormatByName(name string) (inputFormat, bool) {
+	for _, f := range inputFormats {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return inputFormat{}, false
+}
+
+// maxLineLength is generous, for JSON lines holding records with many fields.
+const maxLineLength = 1024 * 1024
+
+func newLineScanner(in io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(in)
+	s.Buffer(nil, maxLineLength)
+	return s
+}
+
+// lineSource calls split on each line, to turn it into items.
+type lineSource struct {
+	scanner *bufio.Scanner
+	line    int
+	split   func(text string, line int) []inputItem
+	pending []inputItem
+}
+
+func (s *lineSource) next() (inputItem, bool) {
+	for len(s.pending) == 0 {
+		if !s.scanner.Scan() {
+			return inputItem{}, false
+		}
+		s.line++
+		s.pending = s.split(s.scanner.Text(), s.line)
+	}
+	item := s.pending[0]
+	s.pending = s.pending[1:]
+	return item, true
+}
+
+func (s *lineSource) err() error { return s.scanner.Err() }
+
+func openLines(in io.Reader, _ inputOptions) (itemSource, error) {
+	return &lineSource{
+		scanner: newLineScanner(in),
+		split: func(text string, line int) []inputItem {
+			if len(text) == 0 {
+				return nil
+			}
+			return []inputItem{{text: text, line: line}}
+		},
+	}, nil
+}
+
+func openComments(in io.Reader, _ inputOptions) (itemSource, error) {
+	return &lineSource{
+		scanner: newLineScanner(in),
+		split: func(text string, line int) []inputItem {
+			if text = stripComment(text); text == "" {
+				return nil
+			}
+			return []inputItem{{text: text, line: line}}
+		},
+	}, nil
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' }
+
+// stripComment removes a comment and the surrounding white-space from a line.
+// Because `#` is valid atext (`#john@example.org` is an address), a `#` only
+// starts a comment at the start of the line or after white-space; `;` is not
+// valid outside quotes, so starts a comment anywhere.  Neither starts a
+// comment within a quoted-string.
+func stripComment(line string) string {
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == ';', c == '#' && (i == 0 || isSpace(line[i-1])):
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// csvSource checks one column of CSV.
+type csvSource struct {
+	rdr    *csv.Reader
+	column int // zero-based
+	name   string
+	fatal  error
+}
+
+func openCSV(in io.Reader, opts inputOptions) (itemSource, error) {
+	if opts.column == "" {
+		return nil, errors.New("the csv input format needs a -column")
+	}
+	s := &csvSource{rdr: csv.NewReader(in), name: opts.column}
+	s.rdr.FieldsPerRecord = -1
+	if n, err := strconv.Atoi(opts.column); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("-column %d: columns are numbered from 1", n)
+		}
+		s.column = n - 1
+		return s, nil
+	}
+	header, err := s.rdr.Read()
+	if err == io.EOF {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.column = -1
+	for i, name := range header {
+		if name == opts.column {
+			s.column = i
+			break
+		}
+	}
+	if s.column < 0 {
+		return nil, fmt.Errorf("no column %q in the CSV header", opts.column)
+	}
+	return s, nil
+}
+
+func (s *csvSource) next() (inputItem, bool) {
+	for s.fatal == nil {
+		record, err := s.rdr.Read()
+		var pe *csv.ParseError
+		switch {
+		case err == io.EOF:
+			return inputItem{}, false
+		case errors.As(err, &pe):
+			return inputItem{line: pe.StartLine, problem: "bad CSV: " + pe.Err.Error()}, true
+		case err != nil:
+			s.fatal = err
+			return inputItem{}, false
+		}
+		line, _ := s.rdr.FieldPos(0)
+		if s.column >= len(record) {
+			return inputItem{line: line, problem: fmt.Sprintf("no column %s in the record", s.name)}, true
+		}
+		if record[s.column] == "" {
+			continue
+		}
+		line, _ = s.rdr.FieldPos(s.column)
+		return inputItem{text: record[s.column], line: line}, true
+	}
+	return inputItem{}, false
+}
+
+func (s *csvSource) err() error { return s.fatal }
+
+func openAliases(in io.Reader, _ inputOptions) (itemSource, error) {
+	return &lineSource{scanner: newLineScanner(in), split: splitAliasLine}, nil
+}
+
+// splitAliasLine returns the targets from one line of an alias file: after the
+// colon of an alias, or all of a continuation line, which starts with
+// white-space.  A comment line starts with `#`.  As in the aliases package, the
+// name may be quoted to hold a colon, and a backslash before a local name (to
+// stop it being expanded as an alias) is not part of it.
+func splitAliasLine(text string, line int) []inputItem {
+	text = strings.TrimSuffix(text, "\r")
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || trimmed[0] == '#' {
+		return nil
+	}
+	if !isSpace(text[0]) {
+		colon := strings.IndexByte(text, ':')
+		if text[0] == '"' {
+			end := closingQuote(text)
+			if end < 0 {
+				return []inputItem{{text: trimmed, line: line, problem: "unterminated quoted alias name"}}
+			}
+			colon = len(text) - len(strings.TrimLeft(text[end+1:], " \t"))
+			if colon >= len(text) || text[colon] != ':' {
+				return []inputItem{{text: trimmed, line: line, problem: "expected ':' after the quoted alias name"}}
+			}
+		}
+		if colon < 0 {
+			return []inputItem{{text: trimmed, line: line, problem: "expected `name: targets`, found no colon"}}
+		}
+		text = text[colon+1:]
+	}
+	var items []inputItem
+	for _, target := range splitTargets(text) {
+		if isNonAddressTarget(target) {
+			continue
+		}
+		if !hasUnquotedAt(target) {
+			items = append(items, inputItem{text: strings.TrimPrefix(target, `\`), line: line, local: true})
+			continue
+		}
+		items = append(items, inputItem{text: target, line: line})
+	}
+	return items
+}
+
+// splitTargets splits on commas outside quotes, dropping empty targets.
+func splitTargets(text string) []string {
+	var targets []string
+	inQuotes := false
+	start := 0
+	add := func(end int) {
+		if t := strings.TrimSpace(text[start:end]); t != "" {
+			targets = append(targets, t)
+		}
+		start = end + 1
+	}
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			add(i)
+		}
+	}
+	add(len(text))
+	return targets
+}
+
+// closingQuote returns the index of the double quote closing the string
+// which starts at the start of s, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func hasUnquotedAt(s string) bool {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inQuotes && c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '@' && !inQuotes:
+			return true
+		}
+	}
+	return false
+}
+
+// isNonAddressTarget is true for the alias targets which deliver to a
+// command, a file or an included list, which may be quoted.
+func isNonAddressTarget(target string) bool {
+	target = strings.TrimPrefix(target, `"`)
+	return strings.HasPrefix(target, "|") || strings.HasPrefix(target, "/") ||
+		strings.HasPrefix(strings.ToLower(target), ":include:")
+}
+
+func openJSONL(in io.Reader, opts inputOptions) (itemSource, error) {
+	if opts.field == "" {
+		return nil, errors.New("the jsonl input format needs a -field")
+	}
+	path := strings.Split(opts.field, ".")
+	return &lineSource{
+		scanner: newLineScanner(in),
+		split: func(text string, line int) []inputItem {
+			if strings.TrimSpace(text) == "" {
+				return nil
+			}
+			return jsonItems(text, line, opts.field, path)
+		},
+	}, nil
+}
+
+func jsonItems(text string, line int, field string, path []string) []inputItem {
+	bad := func(problem string) []inputItem {
+		return []inputItem{{text: text, line: line, problem: problem}}
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return bad("invalid JSON: " + err.Error())
+	}
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return bad(fmt.Sprintf("no field %q", field))
+		}
+		if value, ok = object[key]; !ok {
+			return bad(fmt.Sprintf("no field %q", field))
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []inputItem{{text: v, line: line}}
+	case []interface{}:
+		items := make([]inputItem, 0, len(v))
+		for _, elem := range v {
+			s, ok := elem.(string)
+			if !ok {
+				return bad(fmt.Sprintf("field %q holds a list with a non-string", field))
+			}
+			items = append(items, inputItem{text: s, line: line})
+		}
+		return items
+	}
+	return bad(fmt.Sprintf("field %q is not a string or a list of strings", field))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func readItems(t *testing.T, format, input string, opts inputOptions) []inputItem {
+	t.Helper()
+	f, ok := inputFormatByName(format)
+	if !ok {
+		t.Fatalf("no input format %q", format)
+	}
+	src, err := f.open(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []inputItem
+	for item, ok := src.next(); ok; item, ok = src.next() {
+		items = append(items, item)
+	}
+	if err := src.err(); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+// describeItems gives a compact form for comparison: text@line, with any
+// problem after a bang, and (local) after a bare local part.
+func describeItems(items []inputItem) string {
+	var parts []string
+	for _, item := range items {
+		s := fmt.Sprintf("%s@%d", item.text, item.line)
+		if item.local {
+			s += "(local)"
+		}
+		if item.problem != "" {
+			s += "!" + item.problem
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestStripComment(t *testing.T) {
+	for input, expect := range map[string]string{
+		"john@example.org":                 "john@example.org",
+		"  john@example.org  ":             "john@example.org",
+		"# comment":                        "",
+		"  # comment":                      "",
+		"; comment":                        "",
+		"a#b@example.org":                  "a#b@example.org",
+		"a#b@example.org # comment":        "a#b@example.org",
+		"john@example.org\t#comment":       "john@example.org",
+		"john@example.org;comment":         "john@example.org",
+		`"a # b;c"@example.org ; comment`:  `"a # b;c"@example.org`,
+		`"a \" # b"@example.org # comment`: `"a \" # b"@example.org`,
+	} {
+		if got := stripComment(input); got != expect {
+			t.Errorf("stripComment(%q) gave %q, expected %q", input, got, expect)
+		}
+	}
+}
+
+func TestInputFormats(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		opts   inputOptions
+		input  string
+		expect string
+	}{
+		{"lines", inputOptions{}, "a\n\n b # c\n", "a@1 |  b # c@3"},
+		{"comments", inputOptions{}, "a\n# x\n b # c\n#d\n", "a@1 | b@3"},
+		{"csv", inputOptions{column: "2"}, "a,b\nc,d\n\ne\nf,\n", "b@1 | d@2 | @4!no column 2 in the record"},
+		{"csv", inputOptions{column: "mail"}, "name,mail\n\"x, y\",z\n\"p\nq\",r\n", "z@2 | r@4"},
+		{"aliases", inputOptions{}, "# c\npostmaster: root\nlist: a, \"b c\"@d.e,\n\t\"|prog\", /dev/null\n  :include:/x, f\nbad\n",
+			`root@2(local) | a@3(local) | "b c"@d.e@3 | f@5(local) | bad@6!expected ` + "`name: targets`" + `, found no colon`},
+		{"aliases", inputOptions{}, "\"my:list\": jane, \\root, \"x:y\"@example.org\r\n\"a b\" : c\n",
+			`jane@1(local) | root@1(local) | "x:y"@example.org@1 | c@2(local)`},
+		{"aliases", inputOptions{}, "\"open: x\n\"name\" x: y\n",
+			`"open: x@1!unterminated quoted alias name | "name" x: y@2!expected ':' after the quoted alias name`},
+		{"jsonl", inputOptions{field: "a.b"}, "{\"a\":{\"b\":\"x\"}}\n\n{\"a\":{\"b\":[\"y\",\"z\"]}}\n{\"a\":{\"b\":null}}\n{\"a\":1}\n",
+			`x@1 | y@3 | z@3 | {"a":1}@5!no field "a.b"`},
+	} {
+		got := describeItems(readItems(t, tc.format, tc.input, tc.opts))
+		if got != tc.expect {
+			t.Errorf("%s %+v on %q\n got: %s\nwant: %s", tc.format, tc.opts, tc.input, got, tc.expect)
+		}
+	}
+}
+
+func TestInputFormatOptions(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		opts   inputOptions
+		input  string
+	}{
+		{"csv", inputOptions{}, "a\n"},
+		{"csv", inputOptions{column: "0"}, "a\n"},
+		{"csv", inputOptions{column: "nonesuch"}, "a,b\n"},
+		{"jsonl", inputOptions{}, "{}\n"},
+	} {
+		f, _ := inputFormatByName(tc.format)
+		if _, err := f.open(strings.NewReader(tc.input), tc.opts); err == nil {
+			t.Errorf("%s %+v: accepted", tc.format, tc.opts)
+		}
+	}
+}
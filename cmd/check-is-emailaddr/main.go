@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/philpennock/emailsupport"
 )
@@ -18,42 +19,182 @@ const (
 	EX_NOINPUT = 66
 )
 
-// checker is not safe to share between threads: it prints to stdout, and thus
-// we don't bother making the count field safe either.
+// checker is not safe to share between threads: it writes to a reporter, and
+// thus we don't bother making the count field safe either.  The exception is
+// the check method, which StreamParallel calls from its workers.
 type checker struct {
-	okay  bool
-	count int
+	okay     bool
+	count    int
+	failed   int
+	out      reporter
+	pattern  pattern
+	grammar  *emailsupport.Grammar
+	stats    *stats    // nil unless wanted
+	progress *progress // nil unless wanted
+	extract  bool
 }
 
-func NewChecker() *checker { return &checker{okay: true} }
+func NewChecker(out reporter, p pattern, g *emailsupport.Grammar) *checker {
+	return &checker{okay: true, out: out, pattern: p, grammar: g}
+}
 
-func (c *checker) IsEmailAddress(text string) {
-	if emailsupport.EmailAddress.MatchString(text) {
-		fmt.Printf("OK: %q\n", text)
+// check is safe to call from any goroutine.
+func (c *checker) check(text string) *result {
+	return c.pattern.check(c.grammar, text)
+}
+
+// checkItem is check for an item from an input file, which is also safe to
+// call from any goroutine.  When extracting, there may be any number of
+// results for the item.
+func (c *checker) checkItem(item inputItem, file string) []*result {
+	if item.problem != "" {
+		return []*result{{Input: item.text, Reason: item.problem, File: file, Line: item.line}}
+	}
+	if c.extract {
+		return c.extractFrom(item, file)
+	}
+	var r *result
+	if item.local {
+		r = checkLHS(c.grammar, item.text)
 	} else {
-		fmt.Printf("FAIL: %q\n", text)
+		r = c.check(item.text)
+	}
+	r.File = file
+	r.Line = item.line
+	return []*result{r}
+}
+
+func (c *checker) record(r *result) {
+	c.out.Report(r)
+	if !r.Valid {
 		c.okay = false
+		c.failed += 1
 	}
 	c.count += 1
+	if c.stats != nil {
+		c.stats.add(r)
+	}
+	if c.progress != nil {
+		c.progress.update(c.count, c.failed)
+	}
 }
 
-func (c *checker) Stream(in io.Reader) {
-	rdr := bufio.NewScanner(in)
-	for rdr.Scan() {
-		line := rdr.Text()
-		if len(line) == 0 {
-			continue
+func (c *checker) IsEmailAddress(text string) {
+	c.record(c.check(text))
+}
+
+func (c *checker) Stream(src itemSource, file string) error {
+	for item, ok := src.next(); ok; item, ok = src.next() {
+		for _, r := range c.checkItem(item, file) {
+			c.record(r)
 		}
-		c.IsEmailAddress(line)
 	}
+	return src.err()
 }
 
 func main() {
 	ourName := filepath.Base(os.Args[0])
-	inputFile := flag.String("file", "", "read addresses from file, one per line (no comments, no exceptions except completely blank lines)")
+	inputFile := flag.String("file", "", "read addresses from file, one per line unless -input says otherwise")
+	inputFormatName := flag.String("input", "lines", "the `format` of the -file (see -list)")
+	column := flag.String("column", "", "the `column` of -input csv to check, by number from 1 or by name from the header")
+	field := flag.String("field", "", "the `path` of the field of -input jsonl to check, with dots between the names of nested fields")
+	asJSON := flag.Bool("json", false, "report as JSON, one object per line, with the parts of each address or why it failed")
+	asCSV := flag.Bool("csv", false, "report as CSV, with a header line, with the parts of each address or why it failed")
+	patternName := flag.String("pattern", "address", "check against this `pattern` (see -list)")
+	grammarName := flag.String("grammar", emailsupport.DefaultGrammar().Name(), "the `grammar` for local parts, RFC5321 or RFC2822")
+	extract := flag.Bool("extract", false, "find the addresses in free text and report each with its position, checked against the -pattern, which must be address or strict")
+	list := flag.Bool("list", false, "list the patterns and input formats, then exit")
+	workers := flag.Int("workers", 1, "check a -file with this many `workers`, keeping the output in order; 0 for one per CPU")
+	showProgress := flag.Duration("progress", 0, "report progress on stderr at this `interval` (eg, 10s)")
+	showStats := flag.Bool("stats", false, "report a summary on stderr, with counts per failure reason and per domain")
 	flag.Parse()
 
-	check := NewChecker()
+	if *list {
+		fmt.Println("Patterns:")
+		for _, p := range patterns {
+			fmt.Printf("  %-14s %s\n", p.name, p.description)
+		}
+		fmt.Println("Input formats:")
+		for _, f := range inputFormats {
+			fmt.Printf("  %-14s %s\n", f.name, f.description)
+		}
+		return
+	}
+
+	p, ok := patternByName(*patternName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: unknown pattern %q (see -list)\n", ourName, *patternName)
+		os.Exit(EX_USAGE)
+	}
+	inputFormat, ok := inputFormatByName(*inputFormatName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: unknown input format %q (see -list)\n", ourName, *inputFormatName)
+		os.Exit(EX_USAGE)
+	}
+	if (*column != "") != (inputFormat.name == "csv") || (*field != "") != (inputFormat.name == "jsonl") {
+		fmt.Fprintf(os.Stderr, "%s: -column is needed for, and only for, -input csv; -field for -input jsonl\n", ourName)
+		os.Exit(EX_USAGE)
+	}
+	if *extract && inputFormat.name != "lines" {
+		fmt.Fprintf(os.Stderr, "%s: -extract reads text, so can't be used with -input %s\n", ourName, inputFormat.name)
+		os.Exit(EX_USAGE)
+	}
+	if *extract && p.name != "address" && p.name != "strict" {
+		fmt.Fprintf(os.Stderr, "%s: -extract finds addresses, so can only check them with -pattern address or strict\n", ourName)
+		os.Exit(EX_USAGE)
+	}
+	grammar := emailsupport.GrammarByName(*grammarName)
+	if grammar == nil {
+		fmt.Fprintf(os.Stderr, "%s: unknown grammar %q\n", ourName, *grammarName)
+		os.Exit(EX_USAGE)
+	}
+
+	stdout := bufio.NewWriter(os.Stdout)
+	var out reporter
+	switch {
+	case *asJSON && *asCSV:
+		fmt.Fprintf(os.Stderr, "%s: can't use both -json and -csv\n", ourName)
+		os.Exit(EX_USAGE)
+	case *asJSON:
+		out = newJSONReporter(stdout)
+	case *asCSV:
+		out = newCSVReporter(stdout)
+	default:
+		// plain lines are reported as they always have been, without positions
+		out = &textReporter{out: stdout, positions: *extract || inputFormat.name != "lines"}
+	}
+	if *workers < 0 {
+		fmt.Fprintf(os.Stderr, "%s: -workers can't be negative\n", ourName)
+		os.Exit(EX_USAGE)
+	}
+	if *workers == 0 {
+		*workers = runtime.NumCPU()
+	}
+	check := NewChecker(out, p, grammar)
+	check.extract = *extract
+	if *showStats {
+		check.stats = newStats()
+	}
+	if *showProgress > 0 {
+		check.progress = newProgress(os.Stderr, ourName, *showProgress)
+	}
+	// a failure to read is reported here, but the results from before it, the
+	// progress and the stats are still written before we exit
+	stream := func(in io.Reader, file string) error {
+		src, err := inputFormat.open(in, inputOptions{column: *column, field: *field})
+		if err == nil {
+			if *workers > 1 {
+				err = check.StreamParallel(src, file, *workers)
+			} else {
+				err = check.Stream(src, file)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: reading %q failed: %v\n", ourName, file, err)
+		}
+		return err
+	}
+	var readErr error
 
 	if *inputFile != "" {
 		if len(flag.Args()) > 0 {
@@ -61,7 +202,7 @@ func main() {
 			os.Exit(EX_USAGE)
 		}
 		if *inputFile == "-" {
-			check.Stream(os.Stdin)
+			readErr = stream(os.Stdin, "stdin")
 		} else {
 			fh, err := os.Open(*inputFile)
 			if err != nil {
@@ -70,7 +211,7 @@ func main() {
 			}
 			func(in *os.File) {
 				defer in.Close()
-				check.Stream(fh)
+				readErr = stream(in, *inputFile)
 			}(fh)
 		}
 	} else {
@@ -79,18 +220,48 @@ func main() {
 			os.Exit(EX_USAGE)
 		}
 
-		for _, param := range flag.Args() {
-			check.IsEmailAddress(param)
+		for i, param := range flag.Args() {
+			if *extract {
+				for _, r := range check.checkItem(inputItem{text: param, line: i + 1}, "args") {
+					check.record(r)
+				}
+			} else {
+				check.IsEmailAddress(param)
+			}
 		}
 	}
 
+	err := out.Close()
+	if err == nil {
+		err = stdout.Flush()
+	}
+	if err != nil {
+		// as for a failure to read; we keep to the established exit codes
+		fmt.Fprintf(os.Stderr, "%s: writing results failed: %v\n", ourName, err)
+		os.Exit(EX_DATAERR)
+	}
+	if check.progress != nil {
+		check.progress.finish(check.count, check.failed)
+	}
+	if check.stats != nil {
+		check.stats.write(os.Stderr)
+	}
+
+	if readErr != nil {
+		os.Exit(EX_DATAERR)
+	}
+
 	if !check.okay {
 		os.Exit(1)
 	}
 
 	if check.count == 0 {
 		// given an empty input file, or one containing only blank lines?
-		fmt.Fprintf(os.Stderr, "%s: did not check any addresses\n", ourName)
+		if *extract {
+			fmt.Fprintf(os.Stderr, "%s: did not find any addresses\n", ourName)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: did not check any addresses\n", ourName)
+		}
 		os.Exit(EX_DATAERR)
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// runMainEnv, when set in the environment, makes the test binary run main
+// instead of the tests, so that we can check what the command itself does.
+const runMainEnv = "CHECK_IS_EMAILADDR_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		os.Args = append([]string{"check-is-emailaddr"}, strings.Fields(os.Getenv(runMainEnv))...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runMain(t *testing.T, args, stdin string) (stdout, stderr string, status int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), runMainEnv+"="+args)
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		status = exitErr.ExitCode()
+	default:
+		t.Fatalf("running main failed: %v", err)
+	}
+	return out.String(), errOut.String(), status
+}
+
+func TestReadFailureKeepsEarlierResults(t *testing.T) {
+	input := streamInput(2*parallelBatchSize) + strings.Repeat("x", maxLineLength+1) + "\nlast@example.org\n"
+	for _, args := range []string{"-file - -stats", "-file - -stats -workers 4"} {
+		stdout, stderr, status := runMain(t, args, input)
+		if status != EX_DATAERR {
+			t.Errorf("%s: exited %d, expected %d", args, status, EX_DATAERR)
+		}
+		if !strings.Contains(stdout, "user0@example.org") || !strings.Contains(stdout, "user1020@example.org") {
+			t.Errorf("%s: results from before the overlong line are missing from stdout", args)
+		}
+		if strings.Contains(stdout, "last@example.org") {
+			t.Errorf("%s: reported a line after the overlong one", args)
+		}
+		if !strings.Contains(stderr, "token too long") || !strings.Contains(stderr, "checked 819:") {
+			t.Errorf("%s: stderr lacks the read failure or the stats: %q", args, stderr)
+		}
+	}
+}
+
+func TestExtractNeedsAddressPattern(t *testing.T) {
+	for _, pattern := range []string{"address", "strict"} {
+		if _, stderr, status := runMain(t, "-extract -pattern "+pattern+" john@example.org", ""); status != 0 {
+			t.Errorf("-extract -pattern %s: exited %d: %s", pattern, status, stderr)
+		}
+	}
+	for _, pattern := range []string{"domain", "lhs", "mailbox", "ipv4"} {
+		if _, _, status := runMain(t, "-extract -pattern "+pattern+" john@example.org", ""); status != EX_USAGE {
+			t.Errorf("-extract -pattern %s: exited %d, expected %d", pattern, status, EX_USAGE)
+		}
+	}
+}
+
+func TestAliasesInput(t *testing.T) {
+	input := "postmaster: root\n\"my:list\": jane, \\root, jane@example.org\nbad: john..doe\n"
+	stdout, _, status := runMain(t, "-file - -input aliases", input)
+	if status != 1 {
+		t.Errorf("exited %d, expected 1", status)
+	}
+	for _, want := range []string{`OK: "root"`, `OK: "jane"`, `OK: "jane@example.org"`, `FAIL: "john..doe"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output lacks %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Count(stdout, "FAIL") != 1 {
+		t.Errorf("expected only john..doe to fail:\n%s", stdout)
+	}
+}
+
+func TestTextPositions(t *testing.T) {
+	input := "john@example.org\njohn..doe@example.org\n"
+	if stdout, _, _ := runMain(t, "-file -", input); stdout != "OK: \"john@example.org\"\nFAIL: \"john..doe@example.org\"\n" {
+		t.Errorf("-file with lines changed its output: %q", stdout)
+	}
+	if stdout, _, _ := runMain(t, "-file - -input comments", input); stdout != "OK: \"john@example.org\"\nFAIL: \"john..doe@example.org\" at stdin:2\n" {
+		t.Errorf("-input comments gave no position: %q", stdout)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/philpennock/emailsupport"
+)
+
+// result is what we found out about one input.  The parts are only set for
+// valid input, and the failure fields only for invalid input.
+type result struct {
+	Input      string `json:"input"`
+	Valid      bool   `json:"valid"`
+	LocalPart  string `json:"local_part,omitempty"`
+	Domain     string `json:"domain,omitempty"`
+	DomainType string `json:"domain_type,omitempty"`
+	Production string `json:"production,omitempty"`
+	Offset     *int   `json:"offset,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+}
+
+// reasonOnly is true for a failure which is not from the grammar, such as a
+// length limit or input which could not be checked at all (a line which is
+// not valid JSON, say); there's no telling what went wrong without the reason.
+func (r *result) reasonOnly() bool {
+	return !r.Valid && r.Production == "" && r.Reason != ""
+}
+
+// The domain types
+const (
+	domainName        = "name"
+	domainIPv4Literal = "ipv4-literal"
+	domainIPv6Literal = "ipv6-literal"
+)
+
+// domainType classifies a valid domain.
+func domainType(domain string) string {
+	switch {
+	case !strings.HasPrefix(domain, "["):
+		return domainName
+	case len(domain) > 6 && strings.EqualFold(domain[1:6], "IPv6:"):
+		return domainIPv6Literal
+	default:
+		return domainIPv4Literal
+	}
+}
+
+func (r *result) setAddress(addr emailsupport.Address) {
+	r.Valid = true
+	r.LocalPart = addr.LocalPart
+	r.Domain = addr.Domain
+	r.DomainType = domainType(addr.Domain)
+}
+
+func (r *result) setFailure(err error) {
+	var (
+		pe *emailsupport.ParseError
+		le *emailsupport.LengthError
+	)
+	switch {
+	case errors.As(err, &pe):
+		offset := pe.Offset
+		r.Production = pe.Production
+		r.Offset = &offset
+		r.Reason = pe.Reason
+	case errors.As(err, &le):
+		r.Reason = fmt.Sprintf("%s is %d octets long, exceeding the limit of %d", le.Limit, le.Length, le.Max)
+	default:
+		r.Reason = err.Error()
+	}
+}
+
+// A reporter writes out each result in some format; Close must be called to
+// flush the output and learn of any error in writing it.
+type reporter interface {
+	Report(r *result)
+	Close() error
+}
+
+type textReporter struct {
+	out       io.Writer
+	positions bool // report where in the input each result came from
+	err       error
+}
+
+func (t *textReporter) Report(r *result) {
+	if t.err != nil {
+		return
+	}
+	// when wanted, positions are given for every failure, but only for
+	// addresses which were extracted from text, where they are the point
+	position := ""
+	if t.positions && r.File != "" && (!r.Valid || r.Column != 0) {
+		position = fmt.Sprintf(" at %s:%d", r.File, r.Line)
+		if r.Column != 0 {
+			position += fmt.Sprintf(":%d", r.Column)
+		}
+	}
+	switch {
+	case r.Valid:
+		_, t.err = fmt.Fprintf(t.out, "OK: %q%s\n", r.Input, position)
+	case r.reasonOnly():
+		_, t.err = fmt.Fprintf(t.out, "FAIL: %q%s: %s\n", r.Input, position, r.Reason)
+	default:
+		_, t.err = fmt.Fprintf(t.out, "FAIL: %q%s\n", r.Input, position)
+	}
+}
+
+func (t *textReporter) Close() error { return t.err }
+
+// jsonReporter writes one JSON object per line.
+type jsonReporter struct {
+	enc *json.Encoder
+	err error
+}
+
+func newJSONReporter(out io.Writer) *jsonReporter {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	return &jsonReporter{enc: enc}
+}
+
+func (j *jsonReporter) Report(r *result) {
+	if j.err == nil {
+		j.err = j.enc.Encode(r)
+	}
+}
+
+func (j *jsonReporter) Close() error { return j.err }
+
+var csvHeader = []string{"input", "valid", "local_part", "domain", "domain_type", "production", "offset", "reason", "file", "line", "column"}
+
+// csvReporter writes a header line and then a line per result.
+type csvReporter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVReporter(out io.Writer) *csvReporter {
+	return &csvReporter{w: csv.NewWriter(out)}
+}
+
+func (c *csvReporter) Report(r *result) {
+	if !c.wroteHeader {
+		_ = c.w.Write(csvHeader)
+		c.wroteHeader = true
+	}
+	offset, line, column := "", "", ""
+	if r.Offset != nil {
+		offset = strconv.Itoa(*r.Offset)
+	}
+	if r.Line != 0 {
+		line = strconv.Itoa(r.Line)
+	}
+	if r.Column != 0 {
+		column = strconv.Itoa(r.Column)
+	}
+	// errors are sticky, and returned by Close
+	_ = c.w.Write([]string{
+		r.Input, strconv.FormatBool(r.Valid),
+		r.LocalPart, r.Domain, r.DomainType,
+		r.Production, offset, r.Reason,
+		r.File, line, column,
+	})
+}
+
+func (c *csvReporter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
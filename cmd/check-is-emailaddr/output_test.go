@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/philpennock/emailsupport"
+)
+
+func TestCheckAddress(t *testing.T) {
+	for _, tc := range []struct {
+		input      string
+		valid      bool
+		localPart  string
+		domainType string
+		production string
+		offset     int
+	}{
+		{"john@example.org", true, "john", domainName, "", 0},
+		{`"john doe"@example.org`, true, "john doe", domainName, "", 0},
+		{"john@[192.0.2.1]", true, "john", domainIPv4Literal, "", 0},
+		{"john@[ipv6:2001:db8::42]", true, "john", domainIPv6Literal, "", 0},
+		{"john@example", false, "", "", "Domain", 5},
+		{"john doe@example.org", false, "", "", "Mailbox", 4},
+		{"john@[192.0.2.256]", false, "", "", "Snum", 14},
+	} {
+		r := checkAddress(emailsupport.RFC5321(), tc.input)
+		if r.Valid != tc.valid || r.LocalPart != tc.localPart || r.DomainType != tc.domainType || r.Production != tc.production {
+			t.Errorf("%q: got %+v", tc.input, r)
+			continue
+		}
+		if tc.valid != (r.Offset == nil) {
+			t.Errorf("%q: offset should be set only on failure, got %v", tc.input, r.Offset)
+		} else if r.Offset != nil && *r.Offset != tc.offset {
+			t.Errorf("%q: failed at offset %d, expected %d", tc.input, *r.Offset, tc.offset)
+		}
+	}
+}
+
+var reportInputs = []string{"john@example.org", `"a,b"@example.org`, "john@example"}
+
+func report(out reporter) error {
+	for _, s := range reportInputs {
+		out.Report(checkAddress(emailsupport.RFC5321(), s))
+	}
+	return out.Close()
+}
+
+func TestJSONReporter(t *testing.T) {
+	var b bytes.Buffer
+	if err := report(newJSONReporter(&b)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != len(reportInputs) {
+		t.Fatalf("got %d lines, expected %d: %q", len(lines), len(reportInputs), b.String())
+	}
+	for i, line := range lines {
+		var r result
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Errorf("line %d: %v", i+1, err)
+			continue
+		}
+		if want := checkAddress(emailsupport.RFC5321(), reportInputs[i]); r.Input != want.Input || r.Valid != want.Valid || r.Domain != want.Domain || r.Reason != want.Reason {
+			t.Errorf("line %d: got %+v, expected %+v", i+1, r, want)
+		}
+	}
+	if !strings.Contains(lines[2], `"offset":5`) || strings.Contains(lines[0], "offset") {
+		t.Errorf("offset should be present only on failure: %q", b.String())
+	}
+}
+
+func TestCSVReporter(t *testing.T) {
+	var b bytes.Buffer
+	if err := report(newCSVReporter(&b)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(reportInputs)+1 {
+		t.Fatalf("got %d records, expected a header and %d", len(records), len(reportInputs))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("bad header %q", records[0])
+	}
+	if got := records[2]; got[0] != `"a,b"@example.org` || got[1] != "true" || got[2] != "a,b" {
+		t.Errorf("quoted input mangled: %q", got)
+	}
+	if got := records[3]; got[1] != "false" || got[5] != "Domain" || got[6] != "5" || got[7] == "" {
+		t.Errorf("failure not described: %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"sync"
+)
+
+// parallelBatchSize is how many lines are handed to a worker at a time, so
+// that the cost of the channels is small beside that of the checks.
+const parallelBatchSize = 512
+
+type batch struct {
+	seq     int
+	inputs  []inputItem
+	results []*result
+}
+
+// StreamParallel is Stream, with the checks spread over several workers.
+// The results are recorded in the order of the input, from this goroutine,
+// so the checker's unsafe state is only touched here.  Only a bounded number
+// of batches are in flight at once, so memory use does not grow with the size
+// of the input even when one batch is slow.
+func (c *checker) StreamParallel(src itemSource, file string, workers int) error {
+	maxInFlight := workers * 4
+	tokens := make(chan struct{}, maxInFlight)
+	jobs := make(chan *batch, workers)
+	done := make(chan *batch, maxInFlight)
+
+	go func() {
+		defer close(jobs)
+		b := &batch{}
+		seq := 0
+		send := func() {
+			tokens <- struct{}{}
+			b.seq = seq
+			seq++
+			jobs <- b
+			b = &batch{}
+		}
+		for item, ok := src.next(); ok; item, ok = src.next() {
+			b.inputs = append(b.inputs, item)
+			if len(b.inputs) == parallelBatchSize {
+				send()
+			}
+		}
+		if len(b.inputs) > 0 {
+			send()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				b.results = make([]*result, 0, len(b.inputs))
+				for _, item := range b.inputs {
+					b.results = append(b.results, c.checkItem(item, file)...)
+				}
+				done <- b
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	pending := make(map[int]*batch, maxInFlight)
+	next := 0
+	for b := range done {
+		pending[b.seq] = b
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			for _, r := range ready.results {
+				c.record(r)
+			}
+			<-tokens
+		}
+	}
+	// the reader has finished, as all the batches it sent are done
+	return src.err()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philpennock/emailsupport"
+)
+
+func streamInput(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		switch i % 5 {
+		case 0:
+			fmt.Fprintf(&b, "user%d@example.org\n", i)
+		case 1:
+			fmt.Fprintf(&b, "user %d@example.org\n", i)
+		case 2:
+			fmt.Fprintf(&b, "\n")
+		case 3:
+			fmt.Fprintf(&b, "user%d@Example.ORG\n", i)
+		case 4:
+			fmt.Fprintf(&b, "user%d@example\n", i)
+		}
+	}
+	return b.String()
+}
+
+func runStream(t *testing.T, input string, workers int) (string, *checker) {
+	t.Helper()
+	var out bytes.Buffer
+	p, _ := patternByName("address")
+	c := NewChecker(newJSONReporter(&out), p, emailsupport.RFC5321())
+	c.stats = newStats()
+	src, _ := openLines(strings.NewReader(input), inputOptions{})
+	var err error
+	if workers == 1 {
+		err = c.Stream(src, "input")
+	} else {
+		err = c.StreamParallel(src, "input", workers)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String(), c
+}
+
+func TestStreamParallelKeepsOrder(t *testing.T) {
+	for _, n := range []int{0, 1, parallelBatchSize - 1, parallelBatchSize, 10*parallelBatchSize + 7} {
+		input := streamInput(n)
+		want, seq := runStream(t, input, 1)
+		for _, workers := range []int{2, 3, 8} {
+			got, par := runStream(t, input, workers)
+			if got != want {
+				t.Errorf("%d lines with %d workers: output differs from sequential", n, workers)
+			}
+			if par.count != seq.count || par.failed != seq.failed || par.okay != seq.okay {
+				t.Errorf("%d lines with %d workers: counted %d/%d/%v, sequential %d/%d/%v",
+					n, workers, par.count, par.failed, par.okay, seq.count, seq.failed, seq.okay)
+			}
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	_, c := runStream(t, streamInput(100), 2)
+	s := c.stats
+	if s.valid != 40 || s.invalid != 40 {
+		t.Errorf("got %d valid and %d invalid, expected 40 and 40", s.valid, s.invalid)
+	}
+	if len(s.domains) != 1 || s.domains["example.org"] != 40 {
+		t.Errorf("domains not folded together: %v", s.domains)
+	}
+	if len(s.reasons) != 2 || s.reasons["bad Domain: need at least two labels in a domain"] != 20 {
+		t.Errorf("unexpected reasons: %v", s.reasons)
+	}
+	var b bytes.Buffer
+	s.write(&b)
+	if !strings.HasPrefix(b.String(), "checked 80: 40 OK, 40 failed\n") {
+		t.Errorf("bad summary: %q", b.String())
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/philpennock/emailsupport"
+)
+
+// A pattern is something which we can check input against; check returns the
+// result for the text, using the grammar for any local part.
+type pattern struct {
+	name        string
+	description string
+	check       func(g *emailsupport.Grammar, text string) *result
+}
+
+var patterns = []pattern{
+	{"address", "an email address, as in SMTP (EmailAddress)", checkAddress},
+	{"strict", "an email address within the RFC5321 length limits (EmailAddressStrict)", checkStrict},
+	{"unqualified", "an address or a bare local part, as in mail configuration files (EmailAddressOrUnqualified)", checkUnqualified},
+	{"lhs", "the local part of an address (EmailLHS)", checkLHS},
+	{"domain", "the domain of an address, including address-literals (EmailDomain)", checkDomain},
+	{"mailbox", "an RFC5322 header mailbox, such as `John Doe <john@example.org>`", checkMailbox},
+	{"ipv4", "an IPv4 address (IPv4Address)", validateOnly(emailsupport.ValidateIPv4Address)},
+	{"ipv6", "an IPv6 address (IPv6Address)", validateOnly(emailsupport.ValidateIPv6Address)},
+	{"ipv6-scoped", "an IPv6 address, with an optional zone (IPv6AddressScoped)", validateOnly(emailsupport.ValidateIPv6AddressScoped)},
+	{"netblock", "an IPv4 or IPv6 netblock in CIDR notation (IPNetblock)", validateOnly(emailsupport.ValidateIPNetblock)},
+	{"ipv4-netblock", "an IPv4 netblock in CIDR notation (IPv4Netblock)", validateOnly(emailsupport.ValidateIPv4Netblock)},
+	{"ipv6-netblock", "an IPv6 netblock in CIDR notation (IPv6Netblock)", validateOnly(emailsupport.ValidateIPv6Netblock)},
+}
+
+func patternByName(name string) (pattern, bool) {
+	for _, p := range patterns {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return pattern{}, false
+}
+
+func checkAddress(g *emailsupport.Grammar, text string) *result {
+	r := &result{Input: text}
+	addr, err := g.ParseAddress(text)
+	if err != nil {
+		r.setFailure(err)
+		return r
+	}
+	r.setAddress(addr)
+	return r
+}
+
+func checkStrict(g *emailsupport.Grammar, text string) *result {
+	r := checkAddress(g, text)
+	if !r.Valid {
+		return r
+	}
+	if err := g.ValidateEmailAddressStrict(text); err != nil {
+		r = &result{Input: text}
+		r.setFailure(err)
+	}
+	return r
+}
+
+func checkUnqualified(g *emailsupport.Grammar, text string) *result {
+	r := &result{Input: text}
+	if err := g.ValidateEmailAddressOrUnqualified(text); err != nil {
+		r.setFailure(err)
+		return r
+	}
+	if addr, err := g.ParseAddress(text); err == nil {
+		r.setAddress(addr)
+		return r
+	}
+	r.Valid = true
+	r.LocalPart = unquoteLocalPart(text)
+	return r
+}
+
+func checkLHS(g *emailsupport.Grammar, text string) *result {
+	r := &result{Input: text}
+	if err := g.ValidateEmailLHS(text); err != nil {
+		r.setFailure(err)
+		return r
+	}
+	r.Valid = true
+	r.LocalPart = unquoteLocalPart(text)
+	return r
+}
+
+func checkDomain(_ *emailsupport.Grammar, text string) *result {
+	r := &result{Input: text}
+	if err := emailsupport.ValidateEmailDomain(text); err != nil {
+		r.setFailure(err)
+		return r
+	}
+	r.Valid = true
+	r.Domain = text
+	r.DomainType = domainType(text)
+	return r
+}
+
+func checkMailbox(g *emailsupport.Grammar, text string) *result {
+	r := &result{Input: text}
+	hp := emailsupport.HeaderParser{Grammar: g}
+	mbox, err := hp.ParseMailbox(text)
+	if err != nil {
+		r.setFailure(err)
+		return r
+	}
+	r.setAddress(mbox.Address)
+	return r
+}
+
+// validateOnly makes a check from a validator for something which has no
+// parts worth reporting.
+func validateOnly(validate func(string) error) func(*emailsupport.Grammar, string) *result {
+	return func(_ *emailsupport.Grammar, text string) *result {
+		r := &result{Input: text}
+		if err := validate(text); err != nil {
+			r.setFailure(err)
+			return r
+		}
+		r.Valid = true
+		return r
+	}
+}
+
+// unquoteLocalPart removes the quoting from a local part which is known to be
+// valid, for consistency with emailsupport.Address.
+func unquoteLocalPart(lhs string) string {
+	if !strings.HasPrefix(lhs, `"`) {
+		return lhs
+	}
+	var b strings.Builder
+	quoted := lhs[1 : len(lhs)-1]
+	for i := 0; i < len(quoted); i++ {
+		if quoted[i] == '\\' {
+			i++
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/philpennock/emailsupport"
+)
+
+func TestPatterns(t *testing.T) {
+	for _, tc := range []struct {
+		pattern    string
+		input      string
+		valid      bool
+		localPart  string
+		domain     string
+		domainType string
+	}{
+		{"address", "john@example.org", true, "john", "example.org", domainName},
+		{"address", "john", false, "", "", ""},
+		{"unqualified", "john", true, "john", "", ""},
+		{"unqualified", `"john \"d\" doe"`, true, `john "d" doe`, "", ""},
+		{"unqualified", "john@[192.0.2.1]", true, "john", "[192.0.2.1]", domainIPv4Literal},
+		{"unqualified", "john@", false, "", "", ""},
+		{"lhs", `"a\\b"`, true, `a\b`, "", ""},
+		{"lhs", "john@example.org", false, "", "", ""},
+		{"domain", "example.org", true, "", "example.org", domainName},
+		{"domain", "[IPv6:::1]", true, "", "[IPv6:::1]", domainIPv6Literal},
+		{"domain", "example", false, "", "", ""},
+		{"mailbox", `"Doe, John" <john@example.org>`, true, "john", "example.org", domainName},
+		{"mailbox", "john@example.org (John Doe)", true, "john", "example.org", domainName},
+		{"mailbox", "John Doe <john@example>", false, "", "", ""},
+		{"ipv4", "192.0.2.1", true, "", "", ""},
+		{"ipv4", "192.0.2.256", false, "", "", ""},
+		{"ipv6", "2001:db8::42", true, "", "", ""},
+		{"ipv6", "fe80::1%eth0", false, "", "", ""},
+		{"ipv6-scoped", "fe80::1%eth0", true, "", "", ""},
+		{"netblock", "192.0.2.0/24", true, "", "", ""},
+		{"netblock", "2001:db8::/32", true, "", "", ""},
+		{"ipv4-netblock", "2001:db8::/32", false, "", "", ""},
+		{"ipv6-netblock", "2001:db8::/129", false, "", "", ""},
+	} {
+		p, ok := patternByName(tc.pattern)
+		if !ok {
+			t.Fatalf("no pattern %q", tc.pattern)
+		}
+		r := p.check(emailsupport.RFC5321(), tc.input)
+		if r.Valid != tc.valid || r.LocalPart != tc.localPart || r.Domain != tc.domain || r.DomainType != tc.domainType {
+			t.Errorf("%s %q: got %+v", tc.pattern, tc.input, r)
+		}
+		if !r.Valid && (r.Reason == "" || r.Offset == nil) {
+			t.Errorf("%s %q: failure has no reason: %+v", tc.pattern, tc.input, r)
+		}
+	}
+}
+
+func TestPatternsByGrammar(t *testing.T) {
+	for _, name := range []string{"address", "unqualified", "lhs", "mailbox"} {
+		p, _ := patternByName(name)
+		input := "\"\x01\"@example.org"
+		if name == "lhs" || name == "unqualified" {
+			input = "\"\x01\""
+		}
+		if r := p.check(emailsupport.RFC2822(), input); !r.Valid {
+			t.Errorf("%s: RFC2822 rejected %q: %s", name, input, r.Reason)
+		}
+		if r := p.check(emailsupport.RFC5321(), input); r.Valid {
+			t.Errorf("%s: RFC5321 accepted %q", name, input)
+		}
+	}
+}
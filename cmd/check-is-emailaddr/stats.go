@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsTopDomains is how many of the most common domains the summary lists.
+const statsTopDomains = 20
+
+// stats accumulates the summary of a run: the failures are counted by their
+// reason and the valid inputs by their domain (folded to lower-case).
+type stats struct {
+	valid   int
+	invalid int
+	reasons map[string]int
+	domains map[string]int
+}
+
+func newStats() *stats {
+	return &stats{reasons: make(map[string]int), domains: make(map[string]int)}
+}
+
+func (s *stats) add(r *result) {
+	if !r.Valid {
+		s.invalid++
+		reason := r.Reason
+		if r.Production != "" {
+			reason = "bad " + r.Production + ": " + reason
+		}
+		s.reasons[reason]++
+		return
+	}
+	s.valid++
+	if r.Domain != "" {
+		s.domains[strings.ToLower(r.Domain)]++
+	}
+}
+
+type statsCount struct {
+	key   string
+	count int
+}
+
+// sortedCounts returns the counts from most to least common, and in order of
+// the key for those equally common.
+func sortedCounts(m map[string]int) []statsCount {
+	counts := make([]statsCount, 0, len(m))
+	for k, n := range m {
+		counts = append(counts, statsCount{k, n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].count != counts[j].count {
+			return counts[i].count > counts[j].count
+		}
+		return counts[i].key < counts[j].key
+	})
+	return counts
+}
+
+func (s *stats) write(w io.Writer) {
+	fmt.Fprintf(w, "checked %d: %d OK, %d failed\n", s.valid+s.invalid, s.valid, s.invalid)
+	if len(s.reasons) > 0 {
+		fmt.Fprintf(w, "failures by reason:\n")
+		for _, c := range sortedCounts(s.reasons) {
+			fmt.Fprintf(w, "  %10d  %s\n", c.count, c.key)
+		}
+	}
+	if len(s.domains) > 0 {
+		counts := sortedCounts(s.domains)
+		if len(counts) > statsTopDomains {
+			fmt.Fprintf(w, "domains (top %d of %d):\n", statsTopDomains, len(counts))
+			counts = counts[:statsTopDomains]
+		} else {
+			fmt.Fprintf(w, "domains:\n")
+		}
+		for _, c := range counts {
+			fmt.Fprintf(w, "  %10d  %s\n", c.count, c.key)
+		}
+	}
+}
+
+// progress reports how far we have got, no more often than every interval.
+type progress struct {
+	out      io.Writer
+	prefix   string
+	interval time.Duration
+	started  time.Time
+	last     time.Time
+}
+
+func newProgress(out io.Writer, prefix string, interval time.Duration) *progress {
+	now := time.Now()
+	return &progress{out: out, prefix: prefix, interval: interval, started: now, last: now}
+}
+
+func (p *progress) update(count, failed int) {
+	now := time.Now()
+	if now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	p.report(now, count, failed)
+}
+
+func (p *progress) finish(count, failed int) {
+	p.report(time.Now(), count, failed)
+}
+
+func (p *progress) report(now time.Time, count, failed int) {
+	elapsed := now.Sub(p.started)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(count) / elapsed.Seconds()
+	}
+	fmt.Fprintf(p.out, "%s: checked %d, %d failed, in %v (%.0f/s)\n",
+		p.prefix, count, failed, elapsed.Round(100*time.Millisecond), rate)
+}
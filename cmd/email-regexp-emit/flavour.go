@@ -0,0 +1,398 @@
+package main
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// A flavour describes how to spell a pattern for some other regexp engine.
+// The Go flavour is special: it is the pattern text exactly as the package
+// holds it.  The others are printed from the parsed form of that text, so
+// the structure may differ (the Go parser factors common prefixes out of
+// alternations) but the language matched is the same.
+type flavour struct {
+	name        string
+	description string
+
+	group     string // opening a non-capturing group
+	capture   func(name string) string
+	beginText string
+	endText   string
+
+	// escapeRune returns how to write a rune which is not printable ASCII,
+	// or "" if it should be written as-is
+	escapeRune func(r rune) string
+	// extra characters which must be escaped everywhere
+	extraMeta string
+	// POSIX bracket expressions have no escapes at all
+	posixBrackets bool
+	nonGreedy     bool
+
+	wrap func(pattern string) string
+}
+
+var flavours = []*flavour{
+	{
+		name:        "go",
+		description: "Go (RE2) syntax, as held in the package",
+	},
+	{
+		name:        "backtick",
+		description: "Go syntax with each backtick written as \\x60, safe within Go raw strings and shell backticks",
+	},
+	{
+		name:        "pcre",
+		description: "Perl-compatible (PCRE), as used by Perl, Exim and Postfix pcre: tables",
+		group:       "(?:",
+		capture:     func(name string) string { return "(?<" + name + ">" },
+		beginText:   `\A`,
+		endText:     `\z`,
+		escapeRune:  escapeRunePCRE,
+		nonGreedy:   true,
+	},
+	{
+		name:          "ere",
+		description:   "POSIX extended regular expressions (egrep); groups always capture, and control characters are written raw",
+		group:         "(",
+		capture:       func(string) string { return "(" },
+		beginText:     `^`,
+		endText:       `$`,
+		escapeRune:    func(rune) string { return "" },
+		posixBrackets: true,
+	},
+	{
+		name:        "js",
+		description: "JavaScript regular expression literal, /.../ with slashes escaped",
+		group:       "(?:",
+		capture:     func(name string) string { return "(?<" + name + ">" },
+		beginText:   `^`,
+		endText:     `$`,
+		escapeRune:  escapeRuneJS,
+		extraMeta:   "/",
+		nonGreedy:   true,
+		wrap:        func(pattern string) string { return "/" + pattern + "/" },
+	},
+	{
+		name:        "python",
+		description: "Python re module syntax",
+		group:       "(?:",
+		capture:     func(name string) string { return "(?P<" + name + ">" },
+		beginText:   `\A`,
+		endText:     `\Z`,
+		escapeRune:  escapeRunePython,
+		nonGreedy:   true,
+	},
+}
+
+func flavourByName(name string) *flavour {
+	for _, f := range flavours {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// render returns the Go pattern text in this flavour.
+func (f *flavour) render(goPattern string) (string, error) {
+	switch f.name {
+	case "go":
+		return goPattern, nil
+	case "backtick":
+		return strings.ReplaceAll(goPattern, "`", `\x60`), nil
+	}
+	re, err := syntax.Parse(goPattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	return f.renderTree(re)
+}
+
+// renderTree returns the parsed pattern in this flavour, which must not be
+// one of those using the Go text as-is.
+func (f *flavour) renderTree(re *syntax.Regexp) (string, error) {
+	var b strings.Builder
+	if err := f.emit(&b, re); err != nil {
+		return "", err
+	}
+	if f.wrap != nil {
+		return f.wrap(b.String()), nil
+	}
+	return b.String(), nil
+}
+
+func (f *flavour) emit(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		b.WriteString(f.group + ")")
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && unicode.SimpleFold(r) != r {
+				f.emitClass(b, foldRanges(r))
+			} else {
+				f.emitLiteral(b, r)
+			}
+		}
+	case syntax.OpCharClass:
+		f.emitClass(b, re.Rune)
+	case syntax.OpBeginText:
+		b.WriteString(f.beginText)
+	case syntax.OpEndText:
+		b.WriteString(f.endText)
+	case syntax.OpCapture:
+		b.WriteString(f.capture(re.Name))
+		if err := f.emit(b, re.Sub[0]); err != nil {
+			return err
+		}
+		b.WriteString(")")
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := f.emitAtom(b, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteString("*")
+		case syntax.OpPlus:
+			b.WriteString("+")
+		case syntax.OpQuest:
+			b.WriteString("?")
+		case syntax.OpRepeat:
+			switch {
+			case re.Max == -1:
+				fmt.Fprintf(b, "{%d,}", re.Min)
+			case re.Min == re.Max:
+				fmt.Fprintf(b, "{%d}", re.Min)
+			default:
+				fmt.Fprintf(b, "{%d,%d}", re.Min, re.Max)
+			}
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			if !f.nonGreedy {
+				return fmt.Errorf("%s flavour has no non-greedy repetition", f.name)
+			}
+			b.WriteString("?")
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				if err := f.emitGroup(b, sub); err != nil {
+					return err
+				}
+			} else if err := f.emit(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			if err := f.emit(b, sub); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s flavour: no support for %v in %q", f.name, re.Op, re.String())
+	}
+	return nil
+}
+
+// emitAtom writes re so that it can be followed by a repetition operator.
+func (f *flavour) emitAtom(b *strings.Builder, re *syntax.Regexp) error {
+	switch {
+	case re.Op == syntax.OpCharClass, re.Op == syntax.OpCapture:
+		return f.emit(b, re)
+	case re.Op == syntax.OpLiteral && len(re.Rune) == 1:
+		return f.emit(b, re)
+	}
+	return f.emitGroup(b, re)
+}
+
+func (f *flavour) emitGroup(b *strings.Builder, re *syntax.Regexp) error {
+	b.WriteString(f.group)
+	if err := f.emit(b, re); err != nil {
+		return err
+	}
+	b.WriteString(")")
+	return nil
+}
+
+const metaChars = `\.+*?()|[]{}^$`
+
+func (f *flavour) emitLiteral(b *strings.Builder, r rune) {
+	switch {
+	case r < utf8RuneSelf && strings.ContainsRune(metaChars+f.extraMeta, r):
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	case r < ' ' || r >= 0x7f:
+		if esc := f.escapeRune(r); esc != "" {
+			b.WriteString(esc)
+			return
+		}
+		b.WriteRune(r)
+	default:
+		b.WriteRune(r)
+	}
+}
+
+const utf8RuneSelf = 0x80
+
+// emitClass writes a character class given as sorted pairs of ranges, as
+// regexp/syntax holds them.
+func (f *flavour) emitClass(b *strings.Builder, ranges []rune) {
+	negated := false
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		negated = true
+		ranges = complementRanges(ranges)
+	}
+	b.WriteByte('[')
+	if negated {
+		b.WriteByte('^')
+	}
+	if f.posixBrackets {
+		emitPOSIXBracket(b, ranges, negated)
+	} else {
+		for i := 0; i < len(ranges); i += 2 {
+			f.emitClassRune(b, ranges[i])
+			if ranges[i+1] != ranges[i] {
+				if ranges[i+1] > ranges[i]+1 {
+					b.WriteByte('-')
+				}
+				f.emitClassRune(b, ranges[i+1])
+			}
+		}
+	}
+	b.WriteByte(']')
+}
+
+func (f *flavour) emitClassRune(b *strings.Builder, r rune) {
+	switch {
+	case strings.ContainsRune(`\]^-[`+f.extraMeta, r):
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	case r < ' ' || r >= 0x7f:
+		if esc := f.escapeRune(r); esc != "" {
+			b.WriteString(esc)
+			return
+		}
+		b.WriteRune(r)
+	default:
+		b.WriteRune(r)
+	}
+}
+
+// emitPOSIXBracket writes the inside of a bracket expression, where there are
+// no escapes: `]` must come first, `-` last, `^` anywhere but first, and `[`
+// must not be followed by `.`, `:` or `=`.  So those four are taken out of the
+// ranges and written separately.
+func emitPOSIXBracket(b *strings.Builder, ranges []rune, negated bool) {
+	const specials = "-[]^" // in order
+	special := map[rune]bool{}
+	var plain []rune
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		for _, c := range specials {
+			if c < lo || c > hi {
+				continue
+			}
+			special[c] = true
+			if c > lo {
+				plain = append(plain, lo, c-1)
+			}
+			lo = c + 1
+		}
+		if lo <= hi {
+			plain = append(plain, lo, hi)
+		}
+	}
+	var out strings.Builder
+	if special[']'] {
+		out.WriteByte(']')
+	}
+	for i := 0; i < len(plain); i += 2 {
+		out.WriteRune(plain[i])
+		if plain[i+1] != plain[i] {
+			if plain[i+1] > plain[i]+1 {
+				out.WriteByte('-')
+			}
+			out.WriteRune(plain[i+1])
+		}
+	}
+	if special['['] {
+		out.WriteByte('[')
+	}
+	if special['^'] && out.Len() == 0 && !negated && special['-'] {
+		// "^" first would negate, but "-" may also come first
+		b.WriteString("-^")
+		return
+	}
+	if special['^'] {
+		out.WriteByte('^')
+	}
+	if special['-'] {
+		out.WriteByte('-')
+	}
+	b.WriteString(out.String())
+}
+
+func complementRanges(ranges []rune) []rune {
+	var out []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			out = append(out, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		out = append(out, next, unicode.MaxRune)
+	}
+	return out
+}
+
+// foldRanges returns the class of all the case-variants of r
+func foldRanges(r rune) []rune {
+	runes := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		runes = append(runes, f)
+	}
+	for i := 1; i < len(runes); i++ {
+		for j := i; j > 0 && runes[j] < runes[j-1]; j-- {
+			runes[j], runes[j-1] = runes[j-1], runes[j]
+		}
+	}
+	var ranges []rune
+	for _, c := range runes {
+		ranges = append(ranges, c, c)
+	}
+	return ranges
+}
+
+func escapeRunePCRE(r rune) string {
+	if r < 0x100 {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+	return fmt.Sprintf(`\x{%x}`, r)
+}
+
+func escapeRuneJS(r rune) string {
+	if r < 0x100 {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+	if r <= 0xffff {
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+	return fmt.Sprintf(`\u{%x}`, r)
+}
+
+func escapeRunePython(r rune) string {
+	switch {
+	case r < 0x100:
+		return fmt.Sprintf(`\x%02x`, r)
+	case r <= 0xffff:
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+	return fmt.Sprintf(`\U%08x`, r)
+}
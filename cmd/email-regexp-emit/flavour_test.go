@@ -0,0 +1,206 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// corpus has inputs for every pattern, including the characters which each
+// flavour must escape; none contains a newline, so that each can be given to
+// the external tools as a line.
+var corpus = []string{
+	"john", "john.doe", "john..doe", ".john", "john+topic", "a~`*&^%$#!_-={|}'/?b",
+	"#", ";", "john doe", `""`, `"john doe"`, `"a\"b"`, `"a\\b"`, `"a\b"`, `"a]b"`,
+	`"a[b"`, `"a^b"`, `"a-b"`, `"a/b"`, `"a$b"`, `"a` + "`" + `b"`, `"unterminated`,
+	"john@example.org", "john.doe@example.org", "john@example", "john@example.org.",
+	"john@[192.0.2.1]", "john@[IPv6:2001:db8::42]", "john@[ipv6:2001:db8::42]",
+	"john@[2001:db8::42]", `"john doe"@example.org`, "john@", "@example.org",
+	"example.org", "a-b.example", "a-.example", "xn--4bi.example", "[192.0.2.1]",
+	"[IPv6:::1]", "[iPv6:::1]",
+	"0.0.0.0", "192.0.2.1", "192.0.2.256", "192.0.2.01", "1.2.3",
+	"::", "::1", "2001:db8::42", "2001:DB8:1234:5678:90ab:cdef:192.0.2.1", "1::2::3",
+	"fe80::1%eth0", "192.0.2.0/24", "192.0.2.0/33", "2001:db8::/32", "2001:db8::/129",
+	"", " ", "x",
+}
+
+// expected is what the package's own anchored regexp says for each input.
+func expected(t *testing.T, name string) []bool {
+	t.Helper()
+	text, err := emitPattern(name, "go", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(text)
+	results := make([]bool, len(corpus))
+	for i, s := range corpus {
+		results[i] = re.MatchString(s)
+	}
+	return results
+}
+
+func compare(t *testing.T, label string, want, got []bool) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d results, expected %d", label, len(got), len(want))
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s on %q gave %v, expected %v", label, corpus[i], got[i], want[i])
+		}
+	}
+}
+
+// goSyntax turns the output of a flavour into something Go can compile, for
+// those flavours which are near enough.
+var goSyntax = map[string]func(string) string{
+	"go":       func(s string) string { return s },
+	"backtick": func(s string) string { return s },
+	"pcre":     func(s string) string { return s },
+	"js": func(s string) string {
+		return strings.TrimSuffix(strings.TrimPrefix(s, "/"), "/")
+	},
+	"python": func(s string) string {
+		return strings.ReplaceAll(s, `\Z`, `\z`)
+	},
+}
+
+func TestFlavoursCompileInGo(t *testing.T) {
+	for _, p := range patterns {
+		want := expected(t, p.name)
+		for _, f := range flavours {
+			convert, ok := goSyntax[f.name]
+			if !ok {
+				continue
+			}
+			text, err := emitPattern(p.name, f.name, true)
+			if err != nil {
+				t.Errorf("%s/%s: %v", p.name, f.name, err)
+				continue
+			}
+			re, err := regexp.Compile(convert(text))
+			if err != nil {
+				t.Errorf("%s/%s: does not compile: %v", p.name, f.name, err)
+				continue
+			}
+			got := make([]bool, len(corpus))
+			for i, s := range corpus {
+				got[i] = re.MatchString(s)
+			}
+			compare(t, p.name+"/"+f.name, want, got)
+		}
+	}
+}
+
+func TestBacktickFlavour(t *testing.T) {
+	for _, p := range patterns {
+		text, err := emitPattern(p.name, "backtick", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(text, "`") {
+			t.Errorf("%s: backtick flavour contains a backtick", p.name)
+		}
+	}
+}
+
+func TestUnanchoredIsDefault(t *testing.T) {
+	for _, f := range flavours {
+		text, err := emitPattern("address", f.name, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, anchor := range []string{`\A`, `\z`, `\Z`, `^`, `$/`} {
+			if strings.HasPrefix(strings.TrimPrefix(text, "/"), anchor) || strings.HasSuffix(text, anchor) {
+				t.Errorf("%s: unanchored output is anchored by %q", f.name, anchor)
+			}
+		}
+	}
+}
+
+func TestUnknownNames(t *testing.T) {
+	if _, err := emitPattern("nonesuch", "go", false); err == nil {
+		t.Errorf("unknown pattern accepted")
+	}
+	if _, err := emitPattern("address", "nonesuch", false); err == nil {
+		t.Errorf("unknown flavour accepted")
+	}
+}
+
+// external tools, each given the pattern as an argument and the corpus on
+// stdin, printing 1 or 0 for each line
+var externalCheckers = map[string][]string{
+	"pcre":   {"perl", "-ne", `BEGIN { $re = shift } chomp; print((/$re/ ? 1 : 0), "\n")`},
+	"python": {"python3", "-c", `import re, sys; r = re.compile(sys.argv[1]); [print(1 if r.search(l.rstrip("\n")) else 0) for l in sys.stdin]`},
+	"js":     {"node", "-e", `const re = eval(process.argv[1]); require("fs").readFileSync(0, "utf8").split("\n").slice(0, -1).forEach(l => console.log(re.test(l) ? 1 : 0))`},
+}
+
+func TestFlavoursWithExternalTools(t *testing.T) {
+	input := strings.Join(corpus, "\n") + "\n"
+	for flavourName, command := range externalCheckers {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			t.Logf("skipping %s: no %s", flavourName, command[0])
+			continue
+		}
+		for _, p := range patterns {
+			text, err := emitPattern(p.name, flavourName, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command(command[0], append(command[1:], text)...)
+			cmd.Stdin = strings.NewReader(input)
+			out, err := cmd.Output()
+			if err != nil {
+				t.Errorf("%s/%s: %s failed: %v", p.name, flavourName, command[0], err)
+				continue
+			}
+			var got []bool
+			for _, line := range strings.Fields(string(out)) {
+				got = append(got, line == "1")
+			}
+			compare(t, p.name+"/"+flavourName+" with "+command[0], expected(t, p.name), got)
+		}
+	}
+}
+
+// TestEREWithGrep uses grep, because Go's POSIX mode still treats a backslash
+// in a bracket expression as an escape, where POSIX says it is literal.
+func TestEREWithGrep(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("no grep")
+	}
+	input := strings.Join(corpus, "\n") + "\n"
+	for _, p := range patterns {
+		text, err := emitPattern(p.name, "ere", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(text, "\n") {
+			// grep takes a newline as separating two patterns
+			t.Logf("skipping %s: the ERE has a newline, from the RFC2822 FWS", p.name)
+			continue
+		}
+		cmd := exec.Command("grep", "-n", "-E", "-e", text)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(input)
+		out, err := cmd.Output()
+		if err != nil {
+			if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 1 {
+				t.Errorf("%s/ere: grep failed: %v", p.name, err)
+				continue
+			}
+		}
+		got := make([]bool, len(corpus))
+		for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+			num, _, _ := strings.Cut(line, ":")
+			if n, err := strconv.Atoi(num); err == nil && n >= 1 && n <= len(corpus) {
+				got[n-1] = true
+			}
+		}
+		compare(t, p.name+"/ere with grep", expected(t, p.name), got)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// A format wraps a pattern in a snippet ready to paste into the configuration
+// of some tool, dealing with that tool's quoting on top of the regexp syntax.
+// Each format implies a flavour.
+type format struct {
+	name        string
+	description string
+	flavour     string
+	snippet     func(p namedPattern, goText, result string) (string, error)
+}
+
+var formats = []*format{
+	{
+		name:        "exim",
+		description: "an Exim macro, protected from string expansion by \\N...\\N",
+		flavour:     "pcre",
+		snippet:     eximSnippet,
+	},
+	{
+		name:        "postfix-pcre",
+		description: "a line for a Postfix pcre: table",
+		flavour:     "pcre",
+		snippet:     postfixPCRESnippet,
+	},
+	{
+		name:        "postfix-regexp",
+		description: "a line for a Postfix regexp: table",
+		flavour:     "ere",
+		snippet:     postfixRegexpSnippet,
+	},
+	{
+		name:        "rspamd",
+		description: "rspamd Lua, creating a regexp object",
+		flavour:     "pcre",
+		snippet:     rspamdSnippet,
+	},
+	{
+		name:        "grep",
+		description: "a grep -E command line, quoted for a POSIX shell",
+		flavour:     "ere",
+		snippet:     grepSnippet,
+	},
+}
+
+func formatByName(name string) *format {
+	for _, f := range formats {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// emitSnippet returns the named pattern wrapped for the named format; result
+// is the right-hand side for table formats.
+func emitSnippet(name, formatName string, anchor bool, result string) (string, error) {
+	p, ok := patternByName(name)
+	if !ok {
+		return "", fmt.Errorf("unknown pattern %q", name)
+	}
+	f := formatByName(formatName)
+	if f == nil {
+		return "", fmt.Errorf("unknown format %q", formatName)
+	}
+	text := p.text
+	if anchor {
+		text = `\A` + text + `\z`
+	}
+	return f.snippet(p, text, result)
+}
+
+// renderWith renders the Go pattern text in a flavour, after letting adjust
+// change a copy of the flavour.
+func renderWith(flavourName, goText string, adjust func(f *flavour)) (string, error) {
+	f := *flavourByName(flavourName)
+	if adjust != nil {
+		adjust(&f)
+	}
+	return f.render(goText)
+}
+
+// renderLineERE renders the pattern as an ERE which fits on one line.  The
+// only newlines in the patterns are in character classes (from the RFC2822
+// folding white-space), and can not be matched by a line-based tool anyway, so
+// they are dropped.
+func renderLineERE(goText string) (string, error) {
+	re, err := syntax.Parse(goText, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	if err := dropNewlines(re); err != nil {
+		return "", err
+	}
+	return flavourByName("ere").renderTree(re)
+}
+
+func dropNewlines(re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '\n' {
+				return fmt.Errorf("pattern has a literal newline, which can not be written on one line")
+			}
+		}
+	case syntax.OpCharClass:
+		var ranges []rune
+		for i := 0; i < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if lo <= '\n' && '\n' <= hi {
+				if lo < '\n' {
+					ranges = append(ranges, lo, '\n'-1)
+				}
+				if hi > '\n' {
+					ranges = append(ranges, '\n'+1, hi)
+				}
+				continue
+			}
+			ranges = append(ranges, lo, hi)
+		}
+		if len(ranges) == 0 {
+			return fmt.Errorf("pattern has a class of only newline, which can not be written on one line")
+		}
+		re.Rune = ranges
+	}
+	for _, sub := range re.Sub {
+		if err := dropNewlines(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eximSnippet(p namedPattern, goText, _ string) (string, error) {
+	text, err := renderWith("pcre", goText, nil)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(text, `\N`) {
+		return "", fmt.Errorf("pattern contains \\N, so can not be protected from expansion")
+	}
+	macro := "EMAILSUPPORT_" + strings.ToUpper(p.name)
+	return fmt.Sprintf("# %s; use as, for instance:\n#   condition = ${if match{$sender_address}{%s}}\n%s = \\N%s\\N",
+		p.description, macro, macro, text), nil
+}
+
+func postfixPCRESnippet(p namedPattern, goText, result string) (string, error) {
+	// PCRE takes \/ as a literal slash, even in a character class
+	text, err := renderWith("pcre", goText, func(f *flavour) { f.extraMeta = "/" })
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("# %s\n/%s/ %s", p.description, text, result), nil
+}
+
+// postfixDelimiters are the choices for the regexp: table delimiter, which
+// can be any non-alphanumeric which has no special meaning.  Postfix leaves a
+// backslash before an escaped delimiter, which in a POSIX bracket expression
+// would add a backslash to the class, so we pick one not in the pattern.
+const postfixDelimiters = `/%,;<>=~|@:`
+
+func postfixRegexpSnippet(p namedPattern, goText, result string) (string, error) {
+	text, err := renderLineERE(goText)
+	if err != nil {
+		return "", err
+	}
+	for _, delim := range postfixDelimiters {
+		if !strings.ContainsRune(text, delim) {
+			return fmt.Sprintf("# %s\n%c%s%c %s", p.description, delim, text, delim, result), nil
+		}
+	}
+	return "", fmt.Errorf("every delimiter in %q is used in the pattern", postfixDelimiters)
+}
+
+func rspamdSnippet(p namedPattern, goText, _ string) (string, error) {
+	text, err := renderWith("pcre", goText, func(f *flavour) { f.extraMeta = "/" })
+	if err != nil {
+		return "", err
+	}
+	// a Lua long string has no escapes; pick a level not closed by the text
+	level := ""
+	for strings.Contains("/"+text+"/", "]"+level+"]") {
+		level += "="
+	}
+	return fmt.Sprintf("-- %s\nlocal rspamd_regexp = require \"rspamd_regexp\"\nlocal emailsupport_%s = rspamd_regexp.create_cached([%s[/%s/]%s])",
+		p.description, p.name, level, text, level), nil
+}
+
+func grepSnippet(p namedPattern, goText, _ string) (string, error) {
+	text, err := renderLineERE(goText)
+	if err != nil {
+		return "", err
+	}
+	return "LC_ALL=C grep -E -e " + shellQuote(text), nil
+}
+
+// shellQuote quotes for a POSIX shell, where nothing is special within single
+// quotes except the single quote itself.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
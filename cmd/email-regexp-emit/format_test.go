@@ -0,0 +1,170 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func snippetLine(t *testing.T, name, formatName string) string {
+	t.Helper()
+	text, err := emitSnippet(name, formatName, true, "OK")
+	if err != nil {
+		t.Fatalf("%s/%s: %v", name, formatName, err)
+	}
+	lines := strings.Split(text, "\n")
+	return lines[len(lines)-1]
+}
+
+func matchWithGo(t *testing.T, label, pattern string, want []bool) {
+	t.Helper()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.Errorf("%s: does not compile: %v", label, err)
+		return
+	}
+	got := make([]bool, len(corpus))
+	for i, s := range corpus {
+		got[i] = re.MatchString(s)
+	}
+	compare(t, label, want, got)
+}
+
+// matchWithGrep runs the command through the shell with the corpus on stdin,
+// and takes the lines printed as those which matched.
+func matchWithGrep(t *testing.T, label, command string, want []bool) {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader(strings.Join(corpus, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 1 {
+			t.Errorf("%s: failed: %v", label, err)
+			return
+		}
+	}
+	matched := map[string]bool{}
+	if len(out) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+			matched[line] = true
+		}
+	}
+	got := make([]bool, len(corpus))
+	for i, s := range corpus {
+		got[i] = matched[s]
+	}
+	compare(t, label, want, got)
+}
+
+func TestEximFormat(t *testing.T) {
+	for _, p := range patterns {
+		line := snippetLine(t, p.name, "exim")
+		macro, value, ok := strings.Cut(line, " = ")
+		if !ok || macro != "EMAILSUPPORT_"+strings.ToUpper(p.name) {
+			t.Errorf("%s: bad macro line %q", p.name, line)
+			continue
+		}
+		if !strings.HasPrefix(value, `\N`) || !strings.HasSuffix(value, `\N`) {
+			t.Errorf("%s: macro value not protected by \\N: %q", p.name, value)
+			continue
+		}
+		pcre, err := emitPattern(p.name, "pcre", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inner := value[2 : len(value)-2]; inner != pcre {
+			t.Errorf("%s: macro holds %q, expected %q", p.name, inner, pcre)
+		}
+	}
+}
+
+func TestPostfixPCREFormat(t *testing.T) {
+	for _, p := range patterns {
+		line := snippetLine(t, p.name, "postfix-pcre")
+		if !strings.HasPrefix(line, "/") || !strings.HasSuffix(line, "/ OK") {
+			t.Errorf("%s: bad table line %q", p.name, line)
+			continue
+		}
+		body := line[1 : len(line)-len("/ OK")]
+		if unescaped := strings.ReplaceAll(body, `\\`, ""); strings.Count(unescaped, "/") != strings.Count(unescaped, `\/`) {
+			t.Errorf("%s: unescaped slash in %q", p.name, body)
+		}
+		matchWithGo(t, p.name+"/postfix-pcre", body, expected(t, p.name))
+	}
+}
+
+func TestPostfixRegexpFormat(t *testing.T) {
+	for _, p := range patterns {
+		line := snippetLine(t, p.name, "postfix-regexp")
+		delim := line[:1]
+		if !strings.Contains(postfixDelimiters, delim) || !strings.HasSuffix(line, delim+" OK") {
+			t.Errorf("%s: bad table line %q", p.name, line)
+			continue
+		}
+		body := line[1 : len(line)-len(delim+" OK")]
+		if strings.Contains(body, delim) {
+			t.Errorf("%s: delimiter %q appears in %q", p.name, delim, body)
+			continue
+		}
+		if _, err := exec.LookPath("grep"); err != nil {
+			continue
+		}
+		matchWithGrep(t, p.name+"/postfix-regexp", "grep -E -e "+shellQuote(body), expected(t, p.name))
+	}
+}
+
+var rspamdCreate = regexp.MustCompile(`^local emailsupport_\w+ = rspamd_regexp\.create_cached\(\[(=*)\[/(.*)/\](=*)\]\)$`)
+
+func TestRspamdFormat(t *testing.T) {
+	for _, p := range patterns {
+		line := snippetLine(t, p.name, "rspamd")
+		m := rspamdCreate.FindStringSubmatch(line)
+		if m == nil || m[1] != m[3] {
+			t.Errorf("%s: bad Lua line %q", p.name, line)
+			continue
+		}
+		if strings.Contains(m[2], "]"+m[1]+"]") {
+			t.Errorf("%s: pattern closes the long string: %q", p.name, line)
+		}
+		matchWithGo(t, p.name+"/rspamd", m[2], expected(t, p.name))
+	}
+}
+
+func TestGrepFormat(t *testing.T) {
+	if _, err := exec.LookPath("grep"); err != nil {
+		t.Skip("no grep")
+	}
+	for _, p := range patterns {
+		matchWithGrep(t, p.name+"/grep", snippetLine(t, p.name, "grep"), expected(t, p.name))
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	for _, formatName := range []string{"postfix-pcre", "postfix-regexp"} {
+		text, err := emitSnippet("address", formatName, true, "REJECT no thanks")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(text, " REJECT no thanks") {
+			t.Errorf("%s: result missing from %q", formatName, text)
+		}
+	}
+	if _, err := emitSnippet("address", "nonesuch", false, "OK"); err == nil {
+		t.Errorf("unknown format accepted")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, s := range []string{"", "a", "it's", "'", `$x "y" \z` + "`w`"} {
+		out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(s)).Output()
+		if err != nil {
+			t.Skipf("no sh: %v", err)
+		}
+		if string(out) != s {
+			t.Errorf("shellQuote(%q) came back as %q", s, out)
+		}
+	}
+}
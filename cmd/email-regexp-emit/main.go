@@ -1,17 +1,137 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/philpennock/emailsupport"
 )
 
-// TODO:
-// Flags:
-//  • / surrounds and replace internal "/" with "\/" (as emitted)
-//  • replace "`" with "\x60"
-//  • anchor
-//  • name the pattern extractors (lhs, domain, ipv4, ipv6, etc)
+const (
+	// sysexits.h
+	EX_USAGE    = 64
+	EX_SOFTWARE = 70
+)
+
+type namedPattern struct {
+	name        string
+	description string
+	text        string
+}
+
+var patterns = []namedPattern{
+	{"address", "an email address, as in SMTP (EmailAddress)", emailsupport.TxtEmailAddress},
+	{"lhs", "the local part of an address (EmailLHS)", emailsupport.TxtEmailLHS},
+	{"domain", "the domain of an address, including address-literals (EmailDomain)", emailsupport.TxtEmailDomain},
+	{"unqualified", "an address or a bare local part (EmailAddressOrUnqualified)", emailsupport.TxtEmailAddressOrUnqualified},
+	{"ipv4", "an IPv4 address (IPv4Address)", emailsupport.TxtIPv4Address},
+	{"ipv6", "an IPv6 address (IPv6Address)", emailsupport.TxtIPv6Address},
+	{"netblock", "an IPv4 or IPv6 netblock in CIDR notation (IPNetblock)", emailsupport.TxtIPNetblock},
+}
+
+func patternByName(name string) (namedPattern, bool) {
+	for _, p := range patterns {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return namedPattern{}, false
+}
+
+// emitPattern returns the named pattern in the named flavour.
+func emitPattern(name, flavourName string, anchor bool) (string, error) {
+	p, ok := patternByName(name)
+	if !ok {
+		return "", fmt.Errorf("unknown pattern %q", name)
+	}
+	f := flavourByName(flavourName)
+	if f == nil {
+		return "", fmt.Errorf("unknown flavour %q", flavourName)
+	}
+	text := p.text
+	if anchor {
+		// as the package does for the anchored forms
+		text = `\A` + text + `\z`
+	}
+	return f.render(text)
+}
+
+func listChoices() {
+	fmt.Println("Patterns:")
+	for _, p := range patterns {
+		fmt.Printf("  %-12s %s\n", p.name, p.description)
+	}
+	fmt.Println("Flavours:")
+	for _, f := range flavours {
+		fmt.Printf("  %-12s %s\n", f.name, f.description)
+	}
+	fmt.Println("Formats:")
+	for _, f := range formats {
+		fmt.Printf("  %-15s %s (%s)\n", f.name, f.description, f.flavour)
+	}
+}
+
 func main() {
-	fmt.Println(emailsupport.TxtEmailAddress)
+	ourName := filepath.Base(os.Args[0])
+	flavourName := flag.String("flavour", "go", "regexp `flavour` to emit (see -list)")
+	anchor := flag.Bool("anchor", false, "anchor the pattern to the start and end of the text")
+	formatName := flag.String("format", "", "wrap the pattern in a configuration snippet in this `format` (see -list)")
+	result := flag.String("result", "OK", "the `result` for formats which are lookup tables")
+	list := flag.Bool("list", false, "list the patterns, flavours and formats, then exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [pattern]\n", ourName)
+		fmt.Fprintf(flag.CommandLine.Output(), "The pattern defaults to \"address\".\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		listChoices()
+		return
+	}
+
+	name := "address"
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		name = flag.Arg(0)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: at most one pattern name\n", ourName)
+		os.Exit(EX_USAGE)
+	}
+
+	if _, ok := patternByName(name); !ok {
+		fmt.Fprintf(os.Stderr, "%s: unknown pattern %q (see -list)\n", ourName, name)
+		os.Exit(EX_USAGE)
+	}
+	if flavourByName(*flavourName) == nil {
+		fmt.Fprintf(os.Stderr, "%s: unknown flavour %q (see -list)\n", ourName, *flavourName)
+		os.Exit(EX_USAGE)
+	}
+
+	var text string
+	var err error
+	if *formatName != "" {
+		f := formatByName(*formatName)
+		if f == nil {
+			fmt.Fprintf(os.Stderr, "%s: unknown format %q (see -list)\n", ourName, *formatName)
+			os.Exit(EX_USAGE)
+		}
+		flag.Visit(func(fl *flag.Flag) {
+			if fl.Name == "flavour" && fl.Value.String() != f.flavour {
+				fmt.Fprintf(os.Stderr, "%s: format %q implies flavour %q\n", ourName, f.name, f.flavour)
+				os.Exit(EX_USAGE)
+			}
+		})
+		text, err = emitSnippet(name, *formatName, *anchor, *result)
+	} else {
+		text, err = emitPattern(name, *flavourName, *anchor)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", ourName, err)
+		os.Exit(EX_SOFTWARE)
+	}
+	fmt.Println(text)
 }
@@ -26,7 +26,11 @@ Each regular expression is available as a pattern string in a form with a
 `Txt` prefix, which can be used to build larger regular expressions.  The
 pattern is wrapped with `(?:...)` to be a non-capturing group which can be
 qualified or otherwise treated as a single unit.  No regular expression has
-any capturing groups, letting the caller manage capturing indices.
+any capturing groups, letting the caller manage capturing indices.  The one
+exception is the `Capturing` forms, `EmailAddressCapturing`,
+`EmailAddressOrUnqualifiedCapturing` and `EmailDomainCapturing`, which have
+named groups `lhs`, `domain`, `ipv4literal` and `ipv6literal`; use
+`CaptureAddress` to get them back as a `CapturedAddress`.
 
 Thus for any `Foo`, this package provides:
 
@@ -48,9 +52,30 @@ The list includes:
  * `EmailLHS`: the Left-Hand-Side (or "local part") of an email address; this
    handles unquoted and quoted forms.
  * `EmailAddressOrUnqualified`: either an address or a LHS, this is a form often
-   used in mail configuration files where a domain is implicit.
+   used in mail configuration files where a domain is implicit; the `aliases`
+   sub-package parses and checks one such kind of file, `/etc/aliases`.
+ * `EmailAddressStrict`: not a regexp, because Go's regexps can not express
+   it, but offering `MatchString`: an `EmailAddress` which is also within the
+   RFC5321 section 4.5.3.1 length limits (64 octets for the local part, 255
+   for the domain, 63 for a label, 254 for the whole address).  There is no
+   `Txt` or `Unanchored` form.  `ValidateEmailAddressStrict` and
+   `Address.CheckLengths` return a `*LengthError` naming the limit exceeded.
+ * `EmailAddressUTF8`, `EmailDomainUTF8`, `EmailLHSUTF8`,
+   `EmailAddressOrUnqualifiedUTF8`: the internationalized (SMTPUTF8) forms of
+   the above, per RFC6531 and RFC6532, permitting UTF-8 in the local part and
+   U-labels in the domain.  These are separate patterns: using them is a
+   choice made by the caller, not a change to the ASCII patterns.
+ * `EmailDomainWithGeneralLiteral`, `EmailAddressWithGeneralLiteral`,
+   `EmailAddressOrUnqualifiedWithGeneralLiteral`: as the forms without the
+   suffix, but also accepting any syntactically valid General-address-literal,
+   `[tag:content]`.  Use `RegisterAddressLiteralTag` to teach the package about
+   a tag, and `ValidateGeneralAddressLiteral` or
+   `ParseAddressWithGeneralLiteral` to check against the registered tags.
 
  * `IPv4Address`, `IPv6Address`: an IPv4 or IPv6 address
+ * `IPv6AddressScoped`: an IPv6 address with an optional `%zone`, as in
+   `fe80::1%eth0`, for configuration files; SMTP does not permit scoped
+   addresses, so this is never used within the email patterns
  * `IPv4Netblock`, `IPv6Netblock`, IPNetblock: a netblock in CIDR prefix/len notation (used
    for source ACLs)
  * `IPv4Octet`: a number 0 to 255
@@ -60,15 +85,89 @@ is a careful copy/paste and edit of a version which has been used and gradually
 debugged for years, including in a tool I released called `emit_ipv6_regexp`.
 
 The patterns used in `EmailLHS` (and thus also in items which include an email
-left-hand-side) can be in one of two forms, and selecting between them is a
-compile-time decision.  The rules can be either those from RFC2822 or those from
-RFC5321.  By default, those from RFC5321 are used.  Build with a `rfc2822`
-build-tag to get the older definitions.  If a future RFC changes the rules
+left-hand-side) can be in one of two forms: the rules can be either those from
+RFC2822 or those from RFC5321.  Both sets are always available, as the
+`Grammar` values returned by `RFC2822()` and `RFC5321()`, each of which has
+its own `EmailLHS`, `EmailAddress` and `EmailAddressOrUnqualified` (with `Txt`
+and `Unanchored` forms), and parsing and validation methods.  A `Grammar` can
+not be changed.  The package-level patterns and functions use
+`DefaultGrammar()`, and selecting that is a compile-time decision.
+By default, the RFC5321 rules are used.  Build with a `rfc2822` build-tag to
+get the older definitions as the default.  If a future RFC changes the rules
 again, then the default patterns in this package may change; the build-tag
 `rfc5321` is currently unused, but is reserved for the future to force
 selecting the rules which are now current.
 It is safe (harmless) to supply that build-tag now (but not together with
 `rfc2822`).
+
+
+PARSING
+
+A regular expression can only tell you whether or not something is an email
+address.  `ParseAddress` accepts the same grammar as `EmailAddress` but returns
+an `Address`, with the local part (unquoted) and domain split apart, so that
+callers do not need to guess which `@` is the separator.  When the text is not
+an address, the error is a `*ParseError` naming the grammar production which
+failed and the byte offset at which it did so.
+
+For each of `EmailAddress`, `EmailLHS`, `EmailDomain`,
+`EmailAddressOrUnqualified`, `IPv4Address` and `IPv6Address` there is a
+corresponding `ValidateFoo` function, using the same hand-written parser
+rather than a regular expression.  These accept exactly the same grammar (the
+tests check that they agree, and there is a fuzz target to keep checking) but
+return a `*ParseError` explaining a rejection, and do not suffer from the
+size of the IPv6 alternation when given hostile input.  The netblock patterns
+have `ValidateIPv4Netblock`, `ValidateIPv6Netblock` and `ValidateIPNetblock`.
+
+`ParseIPv4Address`, `ParseIPv6Address`, `ParseIPv4Netblock`,
+`ParseIPv6Netblock` and `ParseIPNetblock` accept the same syntax as the
+regexps, but return a `netip.Addr` or `netip.Prefix`; the netblock forms also
+report whether host bits are set beyond the prefix length.
+
+A `NetblockSet` evaluates a source ACL built from such netblocks, in the style
+of Exim host lists and Postfix's `mynetworks`: entries may be negated with `!`
+and the first entry to match wins.  `LoadNetblockSetFile` reads one from a
+file, and `Contains` takes time logarithmic in the size of the list.
+
+For the rest of an Exim ACL, `ParseDomainMatchList`, `ParseAddressMatchList`
+and `ParseHostMatchList` handle its domain, address and host lists, such as
+`!+blocked : *@example.org : ^postmaster@`, checking the items against
+`EmailDomain` (but allowing a single label, such as `localhost`), `EmailLHS`
+and the netblock patterns.  Named lists, referred to
+as `+name`, and the meanings of `@` and `@[]`, are held in a `NamedLists`.
+Matching gives a `MatchResult` saying which item decided the answer, and
+through which named lists.
+
+For message headers, `ParseMailbox`, `ParseMailboxList` and `ParseAddressList`
+handle the RFC5322 forms, with display names (decoding RFC2047 encoded-words),
+comments, groups and the obsolete syntax.  The addresses within are still
+held to the rules of `EmailAddress`.
+
+`DomainToASCII` and `DomainToUnicode` convert domains between U-labels and
+A-labels using UTS-46 processing (which is not strict IDNA2008), so that a
+domain can be normalised before matching against `EmailDomain`, or shown to a
+user in its Unicode form.
+This brings in a dependency upon golang.org/x/net/idna.
+
+`Canonicalize` (and `Address.Canonical`) give a canonical form of an address,
+quoting the local part only if needed, lower-casing the domain and normalising
+IP address-literals, so that `Equal` can tell that `"john"@Example.ORG` and
+`john@example.org` are the same mailbox.
+
+`FormatAddress` goes the other way, building an address from a local part and
+a domain held separately, quoting the local part only when it must and
+escaping only what the grammar requires.
+
+`RecipientDelimiters` splits a local part such as `john+topic` into a base and
+a detail (sometimes called sub-addressing or plus-addressing), as Postfix does
+with its `recipient_delimiter`; `Address.WithSubAddress` puts them back
+together, quoting as needed.
+
+A `Normalizer` goes further than `Canonical`, applying what a mail provider is
+known to do: Gmail, for instance, ignores dots in the local part and treats
+googlemail.com as the same domain.  This is for spotting duplicate sign-ups,
+not for deciding where to deliver mail.  `DefaultNormalizer` has a few rules
+built in; `LoadNormalizerFile` reads your own.
 */
 package emailsupport
 
@@ -0,0 +1,58 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatAddress builds an address from a local part and domain, using the
+// DefaultGrammar; see Grammar.FormatAddress.
+func FormatAddress(local, domain string) (string, error) {
+	return defaultGrammar.FormatAddress(local, domain)
+}
+
+// FormatAddress builds an address from a local part and domain, such as might
+// be held in database fields.  The local part is used as a Dot-string if
+// possible, and otherwise as a Quoted-string, using a backslash only for
+// those characters which this grammar does not permit unquoted within a
+// Quoted-string.  An error is returned if the local part holds a character
+// which the grammar can not represent at all, or if the domain is not matched
+// by `EmailDomain`.
+//
+// The result is matched by the grammar's `EmailAddress`, and parsing it
+// returns the same local part and domain.
+func (g *Grammar) FormatAddress(local, domain string) (string, error) {
+	lhs, err := g.formatLocalPart(local)
+	if err != nil {
+		return "", err
+	}
+	if err = ValidateEmailDomain(domain); err != nil {
+		return "", err
+	}
+	return lhs + "@" + domain, nil
+}
+
+func (g *Grammar) formatLocalPart(local string) (string, error) {
+	if isDotString(local) {
+		return local, nil
+	}
+	var b strings.Builder
+	b.Grow(len(local) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(local); i++ {
+		c := local[i]
+		switch {
+		case g.isQContent(c):
+		case g.isQPairFollow(c):
+			b.WriteByte('\\')
+		default:
+			return "", fmt.Errorf("emailsupport: %s can not represent %q (at offset %d) in a local part",
+				g.name, c, i)
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String(), nil
+}
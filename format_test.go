@@ -0,0 +1,90 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestFormatAddress(t *testing.T) {
+	for _, item := range []struct {
+		local, domain string
+		expect        string
+	}{
+		{"john", "example.org", `john@example.org`},
+		{"john.doe", "example.org", `john.doe@example.org`},
+		{"john doe", "example.org", `"john doe"@example.org`},
+		{"john..doe", "example.org", `"john..doe"@example.org`},
+		{".john", "example.org", `".john"@example.org`},
+		{"", "example.org", `""@example.org`},
+		{`a"b\c`, "example.org", `"a\"b\\c"@example.org`},
+		{"a@b", "example.org", `"a@b"@example.org`},
+		{"john", "[IPv6:2001:db8::42]", `john@[IPv6:2001:db8::42]`},
+	} {
+		for _, g := range []*Grammar{RFC2822(), RFC5321()} {
+			got, err := g.FormatAddress(item.local, item.domain)
+			if err != nil {
+				t.Errorf("%s.FormatAddress(%q, %q) failed: %v", g, item.local, item.domain, err)
+				continue
+			}
+			if got != item.expect {
+				t.Errorf("%s.FormatAddress(%q, %q) gave %q, expected %q", g, item.local, item.domain, got, item.expect)
+			}
+		}
+	}
+
+	for _, item := range []struct {
+		local   string
+		expect  string // from RFC2822, empty if an error is expected
+		rfc5321 bool   // whether RFC5321 can represent it
+	}{
+		{"a\x01b", "\"a\x01b\"@example.org", false},
+		{"a\tb", "\"a\tb\"@example.org", false},
+		{"a\x7fb", "\"a\x7fb\"@example.org", false},
+		{"a\x00b", "", false},
+		{"jörg", "", false},
+	} {
+		got, err := RFC2822().FormatAddress(item.local, "example.org")
+		switch {
+		case item.expect == "" && err == nil:
+			t.Errorf("RFC2822.FormatAddress(%q) gave %q, expected failure", item.local, got)
+		case item.expect != "" && got != item.expect:
+			t.Errorf("RFC2822.FormatAddress(%q) gave %q, %v; expected %q", item.local, got, err, item.expect)
+		}
+		if got, err = RFC5321().FormatAddress(item.local, "example.org"); (err == nil) != item.rfc5321 {
+			t.Errorf("RFC5321.FormatAddress(%q) gave %q, %v", item.local, got, err)
+		}
+	}
+
+	for _, domain := range []string{"example", "", "example.org.", "[2001:db8::42]"} {
+		if got, err := FormatAddress("john", domain); err == nil {
+			t.Errorf("FormatAddress(%q, %q) gave %q, expected failure", "john", domain, got)
+		}
+	}
+}
+
+// Everything from the test tables which is a valid local part must survive a
+// round trip.
+func TestFormatAddressRoundTrip(t *testing.T) {
+	for _, g := range []*Grammar{RFC2822(), RFC5321()} {
+		for _, item := range testEmailLHS {
+			p := g.newParser(item.text)
+			lhs, _, err := p.localPart()
+			if err != nil || p.finished("Local-part") != nil {
+				continue
+			}
+			formatted, err := g.FormatAddress(lhs, "example.org")
+			if err != nil {
+				t.Errorf("%s.FormatAddress(%q) failed: %v", g, lhs, err)
+				continue
+			}
+			if !g.EmailAddress().MatchString(formatted) {
+				t.Errorf("%s.FormatAddress(%q) gave %q, which is not an EmailAddress", g, lhs, formatted)
+			}
+			a, err := g.ParseAddress(formatted)
+			if err != nil || a.LocalPart != lhs || a.Domain != "example.org" {
+				t.Errorf("%s.FormatAddress(%q) gave %q, which parsed to %#v, %v", g, lhs, formatted, a, err)
+			}
+		}
+	}
+}
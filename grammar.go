@@ -0,0 +1,198 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Grammar holds the patterns which differ between the RFC2822 and RFC5321
+// rules for the local part of an address, so that one program can use both:
+// for instance, checking legacy message headers with RFC2822 while checking
+// SMTP envelopes with RFC5321.
+//
+// The patterns are returned by methods following the usual `TxtFoo`, `Foo`,
+// `FooUnanchored` convention.  Patterns which do not include a local part
+// (such as `EmailDomain`) are the same for all grammars, so are only
+// available at the package level.  A Grammar can not be changed once made,
+// so that nothing can change how another part of the program parses.
+//
+// The package-level patterns, and functions such as ParseAddress, use the
+// DefaultGrammar, which is RFC5321 unless built with the `rfc2822` build-tag.
+type Grammar struct {
+	name string
+
+	txtEmailLHS                  string
+	txtEmailAddress              string
+	txtEmailAddressOrUnqualified string
+
+	emailLHS                            *regexp.Regexp
+	emailLHSUnanchored                  *regexp.Regexp
+	emailAddress                        *regexp.Regexp
+	emailAddressUnanchored              *regexp.Regexp
+	emailAddressOrUnqualified           *regexp.Regexp
+	emailAddressOrUnqualifiedUnanchored *regexp.Regexp
+
+	// the Capturing forms have named groups; see CapturedAddress
+	txtEmailAddressCapturing                     string
+	txtEmailAddressOrUnqualifiedCapturing        string
+	emailAddressCapturing                        *regexp.Regexp
+	emailAddressCapturingUnanchored              *regexp.Regexp
+	emailAddressOrUnqualifiedCapturing           *regexp.Regexp
+	emailAddressOrUnqualifiedCapturingUnanchored *regexp.Regexp
+
+	// emailAddressStrict also enforces length limits, so is not a regexp
+	emailAddressStrict *StrictMatcher
+
+	// the pattern fragments, used to build variants such as the UTF-8 forms
+	txtQText       string
+	txtQPairFollow string
+	txtWrapFWS     string
+
+	// the same rules, for the hand-written parser
+	isQContent    func(byte) bool
+	isQPairFollow func(byte) bool
+}
+
+var (
+	rfc2822Grammar = newGrammar("RFC2822", txtQTextRFC2822, txtQPairFollowRFC2822, txtWrapFWSRFC2822,
+		isQContentRFC2822, isQPairFollowRFC2822)
+	rfc5321Grammar = newGrammar("RFC5321", txtQTextRFC5321, txtQPairFollowRFC5321, txtWrapFWSRFC5321,
+		isQContentRFC5321, isQPairFollowRFC5321)
+)
+
+func newGrammar(
+	name string,
+	qtext, qpairFollow, wrapFWS string,
+	isQContent, isQPairFollow func(byte) bool,
+) *Grammar {
+	g := &Grammar{
+		name:           name,
+		txtQText:       qtext,
+		txtQPairFollow: qpairFollow,
+		txtWrapFWS:     wrapFWS,
+		isQContent:     isQContent,
+		isQPairFollow:  isQPairFollow,
+	}
+	g.txtEmailLHS = buildEmailLHS(txtAText, qtext, qpairFollow, wrapFWS)
+	g.txtEmailAddress = `(?:(?:` + g.txtEmailLHS + `)@(?:` + TxtEmailDomain + `))`
+	g.txtEmailAddressOrUnqualified = `(?:` + g.txtEmailLHS + `(?:@` + TxtEmailDomain + `)?)`
+
+	g.emailLHSUnanchored = regexp.MustCompile(g.txtEmailLHS)
+	g.emailLHS = regexp.MustCompile(start + g.txtEmailLHS + end)
+	g.emailAddressUnanchored = regexp.MustCompile(g.txtEmailAddress)
+	g.emailAddress = regexp.MustCompile(start + g.txtEmailAddress + end)
+	g.emailAddressOrUnqualifiedUnanchored = regexp.MustCompile(g.txtEmailAddressOrUnqualified)
+	g.emailAddressOrUnqualified = regexp.MustCompile(start + g.txtEmailAddressOrUnqualified + end)
+	g.txtEmailAddressCapturing = `(?:(?P<lhs>` + g.txtEmailLHS + `)@` + TxtEmailDomainCapturing + `)`
+	g.txtEmailAddressOrUnqualifiedCapturing = `(?:(?P<lhs>` + g.txtEmailLHS + `)(?:@` + TxtEmailDomainCapturing + `)?)`
+	g.emailAddressCapturingUnanchored = regexp.MustCompile(g.txtEmailAddressCapturing)
+	g.emailAddressCapturing = regexp.MustCompile(start + g.txtEmailAddressCapturing + end)
+	g.emailAddressOrUnqualifiedCapturingUnanchored = regexp.MustCompile(g.txtEmailAddressOrUnqualifiedCapturing)
+	g.emailAddressOrUnqualifiedCapturing = regexp.MustCompile(start + g.txtEmailAddressOrUnqualifiedCapturing + end)
+	g.emailAddressStrict = &StrictMatcher{grammar: g}
+	return g
+}
+
+// RFC2822 returns the Grammar using the RFC2822 rules for the local part,
+// which permit folding white-space and more characters when quoted.
+func RFC2822() *Grammar { return rfc2822Grammar }
+
+// RFC5321 returns the Grammar using the RFC5321 rules for the local part.
+func RFC5321() *Grammar { return rfc5321Grammar }
+
+// DefaultGrammar returns the Grammar used for the package-level patterns and
+// functions, chosen at compile time.
+func DefaultGrammar() *Grammar { return defaultGrammar }
+
+// Name returns the name of the grammar, "RFC2822" or "RFC5321".
+func (g *Grammar) Name() string { return g.name }
+
+// String returns the name of the grammar.
+func (g *Grammar) String() string { return g.name }
+
+// The patterns of the grammar; see the package-level patterns of the same
+// names.
+
+func (g *Grammar) TxtEmailLHS() string                  { return g.txtEmailLHS }
+func (g *Grammar) TxtEmailAddress() string              { return g.txtEmailAddress }
+func (g *Grammar) TxtEmailAddressOrUnqualified() string { return g.txtEmailAddressOrUnqualified }
+
+func (g *Grammar) EmailLHS() *regexp.Regexp                  { return g.emailLHS }
+func (g *Grammar) EmailLHSUnanchored() *regexp.Regexp        { return g.emailLHSUnanchored }
+func (g *Grammar) EmailAddress() *regexp.Regexp              { return g.emailAddress }
+func (g *Grammar) EmailAddressUnanchored() *regexp.Regexp    { return g.emailAddressUnanchored }
+func (g *Grammar) EmailAddressOrUnqualified() *regexp.Regexp { return g.emailAddressOrUnqualified }
+func (g *Grammar) EmailAddressOrUnqualifiedUnanchored() *regexp.Regexp {
+	return g.emailAddressOrUnqualifiedUnanchored
+}
+
+func (g *Grammar) TxtEmailAddressCapturing() string { return g.txtEmailAddressCapturing }
+func (g *Grammar) TxtEmailAddressOrUnqualifiedCapturing() string {
+	return g.txtEmailAddressOrUnqualifiedCapturing
+}
+func (g *Grammar) EmailAddressCapturing() *regexp.Regexp { return g.emailAddressCapturing }
+func (g *Grammar) EmailAddressCapturingUnanchored() *regexp.Regexp {
+	return g.emailAddressCapturingUnanchored
+}
+func (g *Grammar) EmailAddressOrUnqualifiedCapturing() *regexp.Regexp {
+	return g.emailAddressOrUnqualifiedCapturing
+}
+func (g *Grammar) EmailAddressOrUnqualifiedCapturingUnanchored() *regexp.Regexp {
+	return g.emailAddressOrUnqualifiedCapturingUnanchored
+}
+
+// EmailAddressStrict also enforces the length limits, so is not a regexp.
+func (g *Grammar) EmailAddressStrict() *StrictMatcher { return g.emailAddressStrict }
+
+// GrammarByName returns RFC2822 or RFC5321, given the name (case-insensitive,
+// with or without the "RFC" prefix), or nil if not known.
+func GrammarByName(name string) *Grammar {
+	for _, g := range []*Grammar{rfc2822Grammar, rfc5321Grammar} {
+		if strings.EqualFold(name, g.name) || strings.EqualFold(name, g.name[3:]) {
+			return g
+		}
+	}
+	return nil
+}
+
+func (g *Grammar) newParser(text string) *addrParser {
+	return &addrParser{in: text, grammar: g}
+}
+
+// ParseAddress is the package-level ParseAddress, using this grammar.
+func (g *Grammar) ParseAddress(text string) (Address, error) {
+	return g.newParser(text).address()
+}
+
+// ValidateEmailAddress is the package-level function, using this grammar.
+func (g *Grammar) ValidateEmailAddress(text string) error {
+	_, err := g.ParseAddress(text)
+	return err
+}
+
+// ValidateEmailLHS is the package-level function, using this grammar.
+func (g *Grammar) ValidateEmailLHS(text string) error {
+	p := g.newParser(text)
+	if _, _, err := p.localPart(); err != nil {
+		return err
+	}
+	return p.finished("Local-part")
+}
+
+// ValidateEmailAddressOrUnqualified is the package-level function, using this
+// grammar.
+func (g *Grammar) ValidateEmailAddressOrUnqualified(text string) error {
+	p := g.newParser(text)
+	if _, _, err := p.localPart(); err != nil {
+		return err
+	}
+	if !p.consume('@') {
+		return p.finished("Local-part")
+	}
+	if _, _, err := p.domain(); err != nil {
+		return err
+	}
+	return p.finished("Mailbox")
+}
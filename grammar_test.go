@@ -0,0 +1,66 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestGrammarsTogether(t *testing.T) {
+	for _, g := range []*Grammar{RFC2822(), RFC5321()} {
+		iterateBoolPatternMatch(t, g.EmailLHS(), g.Name()+".EmailLHS", testEmailLHS)
+		iterateBoolPatternMatch(t, g.EmailAddress(), g.Name()+".EmailAddress", testEmailAddress)
+		iterateBoolPatternMatch(t, g.EmailAddressOrUnqualified(), g.Name()+".EmailAddressOrUnqualified", testEmailAddressOrUnqualified)
+	}
+
+	iterateBoolPatternMatch(t, RFC2822().EmailLHS(), "RFC2822.EmailLHS", []boolPatternMatch{
+		{"\"\x01\x02\"", true},
+		{"\"\x01\\\x02\"", true},
+		{"\"a\tb\"", true},
+		{"\"a\x7fb\"", true},
+	})
+	iterateBoolPatternMatch(t, RFC5321().EmailLHS(), "RFC5321.EmailLHS", []boolPatternMatch{
+		{"\"\x01\x02\"", false},
+		{"\"\x01\\\x02\"", false},
+		{"\"a\tb\"", false},
+		{"\"a\x7fb\"", false},
+	})
+	iterateBoolPatternMatch(t, RFC2822().EmailAddress(), "RFC2822.EmailAddress", []boolPatternMatch{
+		{"\"\x01\"@example.org", true},
+	})
+	iterateBoolPatternMatch(t, RFC5321().EmailAddress(), "RFC5321.EmailAddress", []boolPatternMatch{
+		{"\"\x01\"@example.org", false},
+	})
+
+	if _, err := RFC2822().ParseAddress("\"\x01\"@example.org"); err != nil {
+		t.Errorf("RFC2822.ParseAddress failed: %v", err)
+	}
+	if _, err := RFC5321().ParseAddress("\"\x01\"@example.org"); err == nil {
+		t.Errorf("RFC5321.ParseAddress accepted a control character")
+	}
+}
+
+func TestDefaultGrammar(t *testing.T) {
+	if EmailAddress != DefaultGrammar().EmailAddress() || TxtEmailLHS != DefaultGrammar().TxtEmailLHS() {
+		t.Errorf("package-level patterns are not those of the DefaultGrammar %s", DefaultGrammar())
+	}
+	if DefaultGrammar().TxtEmailLHS() == RFC2822().TxtEmailLHS() == (DefaultGrammar() == RFC5321()) {
+		t.Errorf("DefaultGrammar %s has the wrong patterns", DefaultGrammar())
+	}
+}
+
+func TestGrammarByName(t *testing.T) {
+	for name, expect := range map[string]*Grammar{
+		"RFC2822": RFC2822(),
+		"rfc2822": RFC2822(),
+		"2822":    RFC2822(),
+		"RFC5321": RFC5321(),
+		"5321":    RFC5321(),
+		"5322":    nil,
+		"":        nil,
+	} {
+		if got := GrammarByName(name); got != expect {
+			t.Errorf("GrammarByName(%q) gave %v, expected %v", name, got, expect)
+		}
+	}
+}
@@ -0,0 +1,681 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Mailbox is an RFC5322 mailbox, as found in message headers such as From:,
+// To: and Cc:.  The DisplayName has had any RFC2047 encoded-words decoded and
+// any quoting removed.  The text of any comments within the mailbox is kept in
+// Comments, in the order found.  Route holds the domains of an obsolete source
+// route (`<@a.example,@b.example:john@c.example>`), which should be ignored
+// but is kept for completeness.
+type Mailbox struct {
+	DisplayName string
+	Address     Address
+	Comments    []string
+	Route       []string
+}
+
+// Group is an RFC5322 group, such as `Undisclosed recipients:;`, which has a
+// display name and zero or more member mailboxes.
+type Group struct {
+	DisplayName string
+	Members     []Mailbox
+}
+
+// AddressListEntry is one item from an address-list: exactly one of Mailbox
+// and Group will be non-nil.
+type AddressListEntry struct {
+	Mailbox *Mailbox
+	Group   *Group
+}
+
+// String returns the mailbox in a form suitable for use in a message header,
+// encoding the display name if needed.  Comments and any route are dropped.
+func (m Mailbox) String() string {
+	if m.DisplayName == "" {
+		return m.Address.String()
+	}
+	return formatPhrase(m.DisplayName) + " <" + m.Address.String() + ">"
+}
+
+// String returns the group in a form suitable for use in a message header.
+func (g Group) String() string {
+	members := make([]string, len(g.Members))
+	for i := range g.Members {
+		members[i] = g.Members[i].String()
+	}
+	return formatPhrase(g.DisplayName) + ": " + strings.Join(members, ", ") + ";"
+}
+
+// String returns whichever of the mailbox or group is present.
+func (e AddressListEntry) String() string {
+	if e.Group != nil {
+		return e.Group.String()
+	}
+	if e.Mailbox != nil {
+		return e.Mailbox.String()
+	}
+	return ""
+}
+
+// formatPhrase renders a display name as atoms if possible, as a
+// quoted-string if it is ASCII, or else as an RFC2047 encoded-word.
+func formatPhrase(name string) string {
+	plain := name != ""
+	for _, word := range strings.Split(name, " ") {
+		if word == "" {
+			plain = false
+			break
+		}
+		for i := 0; i < len(word); i++ {
+			if !isAText(word[i]) {
+				plain = false
+				break
+			}
+		}
+	}
+	if plain {
+		return name
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 0x80 || c < 0x20 || c == 0x7f:
+			return mime.QEncoding.Encode("utf-8", name)
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// HeaderParser parses RFC5322 address headers.  The zero value is ready to
+// use and decodes those RFC2047 character sets which are built in to the
+// `mime` package; set WordDecoder to handle others.  Obsolete syntax from
+// RFC5322 section 4.4 is accepted.
+//
+// The addresses found are held to the same rules as `EmailAddress`, so while
+// the header syntax is RFC5322, an address with (for instance) a domain which
+// is not a valid SMTP domain will be rejected.  The local part is checked with
+// the DefaultGrammar unless Grammar is set.
+type HeaderParser struct {
+	WordDecoder *mime.WordDecoder
+	Grammar     *Grammar
+}
+
+// ParseMailbox parses a single mailbox, using a zero HeaderParser.
+func ParseMailbox(header string) (Mailbox, error) {
+	return (&HeaderParser{}).ParseMailbox(header)
+}
+
+// ParseMailboxList parses a mailbox-list (eg, a From: header), using a zero
+// HeaderParser.
+func ParseMailboxList(header string) ([]Mailbox, error) {
+	return (&HeaderParser{}).ParseMailboxList(header)
+}
+
+// ParseAddressList parses an address-list (eg, a To: header), using a zero
+// HeaderParser.
+func ParseAddressList(header string) ([]AddressListEntry, error) {
+	return (&HeaderParser{}).ParseAddressList(header)
+}
+
+// ParseMailbox parses the text of a header which should hold exactly one
+// mailbox, such as Sender:.  Errors are of type *ParseError.
+func (hp *HeaderParser) ParseMailbox(header string) (Mailbox, error) {
+	p := hp.newParser(header)
+	m, err := p.mailbox()
+	if err != nil {
+		return Mailbox{}, err
+	}
+	if err = p.finished("mailbox"); err != nil {
+		return Mailbox{}, err
+	}
+	return m, nil
+}
+
+// ParseMailboxList parses the text of a header holding a mailbox-list, such
+// as From:.  Errors are of type *ParseError.
+func (hp *HeaderParser) ParseMailboxList(header string) ([]Mailbox, error) {
+	p := hp.newParser(header)
+	list, err := p.mailboxList(false)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.finished("mailbox-list"); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, p.fail("mailbox-list", 0, "no mailboxes found")
+	}
+	return list, nil
+}
+
+// ParseAddressList parses the text of a header holding an address-list, such
+// as To: or Cc:.  Errors are of type *ParseError.
+func (hp *HeaderParser) ParseAddressList(header string) ([]AddressListEntry, error) {
+	p := hp.newParser(header)
+	var list []AddressListEntry
+	for {
+		if err := p.skipCFWS(); err != nil {
+			return nil, err
+		}
+		if p.consume(',') {
+			// obs-addr-list permits empty elements
+			continue
+		}
+		if _, ok := p.peek(); !ok {
+			break
+		}
+		entry, err := p.address()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entry)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if err := p.finished("address-list"); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, p.fail("address-list", 0, "no addresses found")
+	}
+	return list, nil
+}
+
+func (hp *HeaderParser) newParser(header string) *hdrParser {
+	dec := hp.WordDecoder
+	if dec == nil {
+		dec = new(mime.WordDecoder)
+	}
+	g := hp.Grammar
+	if g == nil {
+		g = defaultGrammar
+	}
+	return &hdrParser{addrParser: *g.newParser(header), dec: dec}
+}
+
+// hdrParser extends addrParser with the RFC5322 productions; comments are
+// accumulated as they are skipped, and collected by whichever mailbox is
+// being parsed.
+type hdrParser struct {
+	addrParser
+	dec      *mime.WordDecoder
+	comments []string
+}
+
+// hdrWord is an atom, quoted-string or a lone "." (as permitted in obsolete
+// phrases and local-parts), with whether it was preceded by CFWS.
+type hdrWord struct {
+	text   string
+	start  int
+	quoted bool
+	dot    bool
+	spaced bool
+}
+
+func isHdrWSP(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// isHdrAText is atext, plus the octets of UTF-8 per RFC6532; the addresses
+// themselves are later subject to the stricter package rules.
+func isHdrAText(c byte) bool {
+	return isAText(c) || c >= 0x80
+}
+
+func (p *hdrParser) skipCFWS() error {
+	for {
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return nil
+		case isHdrWSP(c):
+			p.pos++
+		case c == '(':
+			text, err := p.comment()
+			if err != nil {
+				return err
+			}
+			p.comments = append(p.comments, text)
+		default:
+			return nil
+		}
+	}
+}
+
+// comment returns the text of a comment, which may nest; nested comments are
+// returned with their parentheses.
+func (p *hdrParser) comment() (string, error) {
+	start := p.pos
+	p.pos++
+	depth := 1
+	var b strings.Builder
+	for {
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return "", p.fail("comment", p.pos,
+				fmt.Sprintf("missing ')' for comment opened at offset %d", start))
+		case c == '\\':
+			if p.pos+1 >= len(p.in) {
+				return "", p.fail("quoted-pair", p.pos+1, "nothing to quote")
+			}
+			b.WriteByte(p.in[p.pos+1])
+			p.pos += 2
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return strings.TrimSpace(unfold(b.String())), nil
+			}
+		case c == 0:
+			return "", p.fail("ctext", p.pos, "unexpected NUL")
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+// hdrQuotedString returns the content of a quoted-string, unfolded and with
+// quoted-pairs resolved.
+func (p *hdrParser) hdrQuotedString() (string, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for {
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return "", p.fail("quoted-string", p.pos,
+				fmt.Sprintf("missing closing DQUOTE for string opened at offset %d", start))
+		case c == '"':
+			p.pos++
+			return unfold(b.String()), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.in) || p.in[p.pos+1] == '\r' || p.in[p.pos+1] == '\n' {
+				return "", p.fail("quoted-pair", p.pos+1, "can not quote "+p.describe(p.pos+1))
+			}
+			b.WriteByte(p.in[p.pos+1])
+			p.pos += 2
+		case c == 0:
+			return "", p.fail("qtext", p.pos, "unexpected NUL")
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// unfold removes the CRLF of folding whitespace
+func unfold(s string) string {
+	if strings.IndexAny(s, "\r\n") < 0 {
+		return s
+	}
+	return strings.NewReplacer("\r\n", "", "\r", "", "\n", "").Replace(s)
+}
+
+// words collects a run of atoms, quoted-strings and dots; this covers both
+// (obsolete) phrases and (obsolete) local-parts, which can only be told apart
+// by what follows.
+func (p *hdrParser) words() ([]hdrWord, error) {
+	var words []hdrWord
+	for {
+		before := p.pos
+		if err := p.skipCFWS(); err != nil {
+			return nil, err
+		}
+		w := hdrWord{start: p.pos, spaced: p.pos > before}
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return words, nil
+		case c == '"':
+			text, err := p.hdrQuotedString()
+			if err != nil {
+				return nil, err
+			}
+			w.text, w.quoted = text, true
+		case c == '.':
+			p.pos++
+			w.text, w.dot = ".", true
+		case isHdrAText(c):
+			for c, ok = p.peek(); ok && isHdrAText(c); c, ok = p.peek() {
+				p.pos++
+			}
+			w.text = p.in[w.start:p.pos]
+		default:
+			return words, nil
+		}
+		words = append(words, w)
+	}
+}
+
+// phrase turns words into a display name, decoding encoded-words.  Per
+// RFC2047, whitespace between adjacent encoded-words is dropped.
+func (p *hdrParser) phrase(words []hdrWord, production string) (string, error) {
+	if len(words) > 0 && words[0].dot {
+		return "", p.fail(production, words[0].start, "may not start with '.'")
+	}
+	var b strings.Builder
+	prevEncoded := false
+	for i, w := range words {
+		text := w.text
+		encoded := false
+		switch {
+		case w.quoted:
+			if decoded, err := p.dec.DecodeHeader(text); err == nil {
+				text = decoded
+			}
+		case isEncodedWord(text):
+			if decoded, err := p.dec.Decode(text); err == nil {
+				text, encoded = decoded, true
+			}
+		}
+		if i > 0 && w.spaced && !(encoded && prevEncoded) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		prevEncoded = encoded
+	}
+	return b.String(), nil
+}
+
+func isEncodedWord(s string) bool {
+	return len(s) > 8 && strings.HasPrefix(s, "=?") && strings.HasSuffix(s, "?=") && strings.Count(s, "?") == 4
+}
+
+// address is a mailbox or a group
+func (p *hdrParser) address() (AddressListEntry, error) {
+	start, comments := p.pos, p.comments
+	words, err := p.words()
+	if err != nil {
+		return AddressListEntry{}, err
+	}
+	if c, ok := p.peek(); ok && c == ':' && len(words) > 0 {
+		g, err := p.group(words)
+		if err != nil {
+			return AddressListEntry{}, err
+		}
+		return AddressListEntry{Group: &g}, nil
+	}
+	p.pos, p.comments = start, comments
+	m, err := p.mailbox()
+	if err != nil {
+		return AddressListEntry{}, err
+	}
+	return AddressListEntry{Mailbox: &m}, nil
+}
+
+func (p *hdrParser) group(words []hdrWord) (Group, error) {
+	var (
+		g   Group
+		err error
+	)
+	if g.DisplayName, err = p.phrase(words, "display-name"); err != nil {
+		return Group{}, err
+	}
+	p.pos++ // ':'
+	p.comments = nil
+	if g.Members, err = p.mailboxList(true); err != nil {
+		return Group{}, err
+	}
+	if !p.consume(';') {
+		return Group{}, p.fail("group", p.pos, "expected ';' to end group, found "+p.describe(p.pos))
+	}
+	if err = p.skipCFWS(); err != nil {
+		return Group{}, err
+	}
+	p.comments = nil
+	return g, nil
+}
+
+// mailboxList parses mailboxes separated by commas, permitting the empty
+// elements of obs-mbox-list; within a group, the list ends at a ';'.
+func (p *hdrParser) mailboxList(inGroup bool) ([]Mailbox, error) {
+	var list []Mailbox
+	for {
+		if err := p.skipCFWS(); err != nil {
+			return nil, err
+		}
+		if p.consume(',') {
+			continue
+		}
+		c, ok := p.peek()
+		if !ok || (inGroup && c == ';') {
+			return list, nil
+		}
+		m, err := p.mailbox()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+		if !p.consume(',') {
+			return list, nil
+		}
+	}
+}
+
+func (p *hdrParser) mailbox() (Mailbox, error) {
+	start := p.pos
+	words, err := p.words()
+	if err != nil {
+		return Mailbox{}, err
+	}
+	var m Mailbox
+	c, ok := p.peek()
+	switch {
+	case ok && c == '<':
+		if m.DisplayName, err = p.phrase(words, "display-name"); err != nil {
+			return Mailbox{}, err
+		}
+		if m.Route, m.Address, err = p.angleAddr(); err != nil {
+			return Mailbox{}, err
+		}
+	case ok && c == '@':
+		if m.Address, err = p.addrSpec(words); err != nil {
+			return Mailbox{}, err
+		}
+	case len(words) == 0:
+		return Mailbox{}, p.fail("mailbox", p.pos, "expected mailbox, found "+p.describe(p.pos))
+	default:
+		return Mailbox{}, p.fail("mailbox", start, "expected '<' or '@' after words, found "+p.describe(p.pos))
+	}
+	if err = p.skipCFWS(); err != nil {
+		return Mailbox{}, err
+	}
+	m.Comments = p.comments
+	p.comments = nil
+	return m, nil
+}
+
+func (p *hdrParser) angleAddr() ([]string, Address, error) {
+	open := p.pos
+	p.pos++ // '<'
+	route, err := p.obsRoute()
+	if err != nil {
+		return nil, Address{}, err
+	}
+	words, err := p.words()
+	if err != nil {
+		return nil, Address{}, err
+	}
+	if c, ok := p.peek(); !ok || c != '@' {
+		return nil, Address{}, p.fail("angle-addr", p.pos, "expected '@', found "+p.describe(p.pos))
+	}
+	a, err := p.addrSpec(words)
+	if err != nil {
+		return nil, Address{}, err
+	}
+	// hdrDomain leaves any CFWS after the domain, which is permitted here
+	if err := p.skipCFWS(); err != nil {
+		return nil, Address{}, err
+	}
+	if !p.consume('>') {
+		return nil, Address{}, p.fail("angle-addr", p.pos,
+			fmt.Sprintf("expected '>' to close '<' at offset %d, found %s", open, p.describe(p.pos)))
+	}
+	return route, a, nil
+}
+
+// obsRoute handles the obsolete source route which may start an angle-addr
+func (p *hdrParser) obsRoute() ([]string, error) {
+	start, comments := p.pos, p.comments
+	if err := p.skipCFWS(); err != nil {
+		return nil, err
+	}
+	for p.consume(',') {
+		if err := p.skipCFWS(); err != nil {
+			return nil, err
+		}
+	}
+	if c, ok := p.peek(); !ok || c != '@' {
+		p.pos, p.comments = start, comments
+		return nil, nil
+	}
+	var route []string
+	for {
+		if err := p.skipCFWS(); err != nil {
+			return nil, err
+		}
+		if p.consume('@') {
+			domain, err := p.hdrDomain()
+			if err != nil {
+				return nil, err
+			}
+			route = append(route, domain)
+			if err = p.skipCFWS(); err != nil {
+				return nil, err
+			}
+		}
+		if p.consume(',') {
+			continue
+		}
+		if p.consume(':') {
+			return route, nil
+		}
+		return nil, p.fail("obs-route", p.pos, "expected ',' or ':', found "+p.describe(p.pos))
+	}
+}
+
+// addrSpec takes the words already seen as the local-part, and parses the
+// domain; the result is checked against the package grammar.
+func (p *hdrParser) addrSpec(words []hdrWord) (Address, error) {
+	var a Address
+	if len(words) == 0 {
+		return Address{}, p.fail("local-part", p.pos, "missing before '@'")
+	}
+	var local strings.Builder
+	for i, w := range words {
+		if w.dot != (i%2 == 1) || (i == len(words)-1 && w.dot) {
+			return Address{}, p.fail("local-part", w.start, "words must be separated by single '.'")
+		}
+		if w.quoted {
+			a.QuotedLocalPart = true
+		}
+		local.WriteString(w.text)
+	}
+	a.LocalPart = local.String()
+	if err := p.wrapValidation("local-part", words[0].start, p.grammar.ValidateEmailLHS(a.localPartString())); err != nil {
+		return Address{}, err
+	}
+
+	p.pos++ // '@'
+	if err := p.skipCFWS(); err != nil {
+		return Address{}, err
+	}
+	domainStart := p.pos
+	domain, err := p.hdrDomain()
+	if err != nil {
+		return Address{}, err
+	}
+	if err = p.wrapValidation("domain", domainStart, ValidateEmailDomain(domain)); err != nil {
+		return Address{}, err
+	}
+	a.Domain = domain
+	a.AddressLiteral = strings.HasPrefix(domain, "[")
+	return a, nil
+}
+
+// wrapValidation reports an error from the package validators, which will
+// have an offset within the reconstructed text, at an offset in the header.
+func (p *hdrParser) wrapValidation(production string, offset int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*ParseError); ok {
+		return p.fail(production, offset, fmt.Sprintf("%s (bad %s at offset %d of %q)",
+			pe.Reason, pe.Production, pe.Offset, pe.Input))
+	}
+	return err
+}
+
+// hdrDomain returns a domain with any CFWS (obs-domain) or FWS (in a
+// domain-literal) removed.
+func (p *hdrParser) hdrDomain() (string, error) {
+	if c, ok := p.peek(); ok && c == '[' {
+		start := p.pos
+		var b strings.Builder
+		for {
+			c, ok := p.peek()
+			switch {
+			case !ok:
+				return "", p.fail("domain-literal", p.pos,
+					fmt.Sprintf("missing ']' for literal opened at offset %d", start))
+			case c == '\\':
+				// obs-dtext permits quoted-pair
+				if p.pos+1 >= len(p.in) {
+					return "", p.fail("quoted-pair", p.pos+1, "nothing to quote")
+				}
+				b.WriteByte(p.in[p.pos+1])
+				p.pos += 2
+				continue
+			case isHdrWSP(c):
+			default:
+				b.WriteByte(c)
+			}
+			p.pos++
+			if c == ']' {
+				return b.String(), nil
+			}
+		}
+	}
+	var b strings.Builder
+	for {
+		start := p.pos
+		for c, ok := p.peek(); ok && isHdrAText(c); c, ok = p.peek() {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.fail("domain", p.pos, "expected atom, found "+p.describe(p.pos))
+		}
+		b.WriteString(p.in[start:p.pos])
+		save, comments := p.pos, p.comments
+		if err := p.skipCFWS(); err != nil {
+			return "", err
+		}
+		if !p.consume('.') {
+			p.pos, p.comments = save, comments
+			return b.String(), nil
+		}
+		b.WriteByte('.')
+		if err := p.skipCFWS(); err != nil {
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMailbox(t *testing.T) {
+	for _, item := range []struct {
+		text   string
+		expect Mailbox
+	}{
+		{`john@example.org`, Mailbox{Address: Address{LocalPart: "john", Domain: "example.org"}}},
+		{`John Doe <john@example.org>`, Mailbox{
+			DisplayName: "John Doe",
+			Address:     Address{LocalPart: "john", Domain: "example.org"}}},
+		{`"Doe, John" <john@example.org>`, Mailbox{
+			DisplayName: "Doe, John",
+			Address:     Address{LocalPart: "john", Domain: "example.org"}}},
+		{`<john@example.org>`, Mailbox{Address: Address{LocalPart: "john", Domain: "example.org"}}},
+		{`john@example.org (John Doe)`, Mailbox{
+			Address:  Address{LocalPart: "john", Domain: "example.org"},
+			Comments: []string{"John Doe"}}},
+		{`(lead) John (middle) <john@example.org> (trail (nested))`, Mailbox{
+			DisplayName: "John",
+			Address:     Address{LocalPart: "john", Domain: "example.org"},
+			Comments:    []string{"lead", "middle", "trail (nested)"}}},
+		{`John Q. Public <john.q.public@example.org>`, Mailbox{
+			DisplayName: "John Q. Public",
+			Address:     Address{LocalPart: "john.q.public", Domain: "example.org"}}},
+		{`=?utf-8?q?J=C3=B6rg?= =?utf-8?q?_Schmidt?= <joerg@example.org>`, Mailbox{
+			DisplayName: "Jörg Schmidt",
+			Address:     Address{LocalPart: "joerg", Domain: "example.org"}}},
+		{`"a@b"@example.org`, Mailbox{Address: Address{LocalPart: "a@b", Domain: "example.org", QuotedLocalPart: true}}},
+		{`john . doe @ example . org`, Mailbox{Address: Address{LocalPart: "john.doe", Domain: "example.org"}}},
+		{`john."doe"@example.org`, Mailbox{Address: Address{LocalPart: "john.doe", Domain: "example.org", QuotedLocalPart: true}}},
+		{`<@a.example,@b.example:john@example.org>`, Mailbox{
+			Address: Address{LocalPart: "john", Domain: "example.org"},
+			Route:   []string{"a.example", "b.example"}}},
+		{`john@[ 192.0.2.1 ]`, Mailbox{Address: Address{LocalPart: "john", Domain: "[192.0.2.1]", AddressLiteral: true}}},
+		{"John\r\n Doe <john@example.org>", Mailbox{
+			DisplayName: "John Doe",
+			Address:     Address{LocalPart: "john", Domain: "example.org"}}},
+		{`<john@example.org >`, Mailbox{Address: Address{LocalPart: "john", Domain: "example.org"}}},
+		{`John <john@[192.0.2.1] >`, Mailbox{
+			DisplayName: "John",
+			Address:     Address{LocalPart: "john", Domain: "[192.0.2.1]", AddressLiteral: true}}},
+		{`John <john@example.org(comment)>`, Mailbox{
+			DisplayName: "John",
+			Address:     Address{LocalPart: "john", Domain: "example.org"},
+			Comments:    []string{"comment"}}},
+		// RFC5322 appendix A.5
+		{`Pete(A nice \) chap) <pete(his account)@silly.test(his host)>`, Mailbox{
+			DisplayName: "Pete",
+			Address:     Address{LocalPart: "pete", Domain: "silly.test"},
+			Comments:    []string{"A nice ) chap", "his account", "his host"}}},
+		{`Chris Jones <c@(Chris's host.)public.example>`, Mailbox{
+			DisplayName: "Chris Jones",
+			Address:     Address{LocalPart: "c", Domain: "public.example"},
+			Comments:    []string{"Chris's host."}}},
+	} {
+		got, err := ParseMailbox(item.text)
+		if err != nil {
+			t.Errorf("ParseMailbox(%q) failed: %v", item.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, item.expect) {
+			t.Errorf("ParseMailbox(%q) gave %#v, expected %#v", item.text, got, item.expect)
+		}
+	}
+}
+
+func TestParseMailboxErrors(t *testing.T) {
+	for _, item := range []struct {
+		text       string
+		production string
+		offset     int
+	}{
+		{``, "mailbox", 0},
+		{`John Doe`, "mailbox", 0},
+		{`John <john@example.org`, "angle-addr", 22},
+		{`John <john>`, "angle-addr", 10},
+		{`john@example`, "domain", 5},
+		{`john@example_org.example`, "domain", 5},
+		{`john..doe@example.org`, "local-part", 5},
+		{`(unclosed john@example.org`, "comment", 26},
+		{`"unclosed <john@example.org>`, "quoted-string", 28},
+		{`john@example.org, jane@example.org`, "mailbox", 16},
+	} {
+		_, err := ParseMailbox(item.text)
+		if err == nil {
+			t.Errorf("ParseMailbox(%q) succeeded, expected failure", item.text)
+			continue
+		}
+		pe, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseMailbox(%q) gave non-ParseError %T: %v", item.text, err, err)
+			continue
+		}
+		if pe.Production != item.production || pe.Offset != item.offset {
+			t.Errorf("ParseMailbox(%q) failed in %s at %d, expected %s at %d: %v",
+				item.text, pe.Production, pe.Offset, item.production, item.offset, err)
+		}
+	}
+}
+
+func TestParseMailboxList(t *testing.T) {
+	got, err := ParseMailboxList(`john@example.org, , "Jane" <jane@example.org> (work),`)
+	if err != nil {
+		t.Fatalf("ParseMailboxList failed: %v", err)
+	}
+	expect := []Mailbox{
+		{Address: Address{LocalPart: "john", Domain: "example.org"}},
+		{DisplayName: "Jane", Address: Address{LocalPart: "jane", Domain: "example.org"}, Comments: []string{"work"}},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("ParseMailboxList gave %#v, expected %#v", got, expect)
+	}
+	if _, err := ParseMailboxList(`Friends: john@example.org;`); err == nil {
+		t.Errorf("ParseMailboxList accepted a group")
+	}
+}
+
+func TestParseAddressList(t *testing.T) {
+	for _, item := range []struct {
+		text   string
+		expect []string
+	}{
+		{`Undisclosed recipients:;`, []string{`Undisclosed recipients: ;`}},
+		{`john@example.org`, []string{`john@example.org`}},
+		{`Friends: john@example.org, Jane <jane@example.org>;, bob@example.org`,
+			[]string{`Friends: john@example.org, Jane <jane@example.org>;`, `bob@example.org`}},
+		{`A Group:(empty);, "x y"@example.org`, []string{`A Group: ;`, `"x y"@example.org`}},
+		{`=?iso-8859-1?q?J=F6rg?= <joerg@example.org>`, []string{`=?utf-8?q?J=C3=B6rg?= <joerg@example.org>`}},
+		// RFC5322 appendix A.5
+		{"A Group(Some people)\r\n     :Chris Jones <c@(Chris's host.)public.example>,\r\n" +
+			"         joe@example.org,\r\n  John <jdoe@one.test> (my dear friend); (the end of the group)",
+			[]string{`A Group: Chris Jones <c@public.example>, joe@example.org, John <jdoe@one.test>;`}},
+		{`(Empty list)(start)Hidden recipients  :(nobody(that I know))  ;`, []string{`Hidden recipients: ;`}},
+	} {
+		got, err := ParseAddressList(item.text)
+		if err != nil {
+			t.Errorf("ParseAddressList(%q) failed: %v", item.text, err)
+			continue
+		}
+		strs := make([]string, len(got))
+		for i := range got {
+			strs[i] = got[i].String()
+		}
+		if !reflect.DeepEqual(strs, item.expect) {
+			t.Errorf("ParseAddressList(%q) gave %q, expected %q", item.text, strs, item.expect)
+		}
+	}
+
+	for _, bad := range []string{``, ` , `, `Friends: john@example.org`, `john@example.org; jane@example.org`} {
+		if got, err := ParseAddressList(bad); err == nil {
+			t.Errorf("ParseAddressList(%q) succeeded, giving %v", bad, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// The IDNA conversions use the UTS-46 lookup profile from
+// golang.org/x/net/idna, not strict IDNA2008: the UTS-46 mappings are applied
+// first, so that (for instance) upper-case and full-width characters are
+// folded, and some characters which IDNA2008 disallows, such as symbols like
+// U+2709 ENVELOPE, are accepted for compatibility with IDNA2003.  This is the
+// processing appropriate for a domain which you are about to look up, rather
+// than for deciding whether a domain may be registered.  So DomainToUnicode
+// may return a domain which `EmailDomainUTF8`, which permits only letters,
+// digits and marks, does not match.
+//
+// Address-literals are not domains and are returned unchanged.
+
+// DomainToASCII converts a domain to the form using only A-labels (the
+// `xn--` punycode form), suitable for matching against `EmailDomain` and for
+// use in SMTP without the SMTPUTF8 extension.
+func DomainToASCII(domain string) (string, error) {
+	if strings.HasPrefix(domain, "[") {
+		return domain, nil
+	}
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("emailsupport: converting domain %q to ASCII: %w", domain, err)
+	}
+	return ascii, nil
+}
+
+// DomainToUnicode converts a domain to the form using U-labels, suitable for
+// display to users.
+func DomainToUnicode(domain string) (string, error) {
+	if strings.HasPrefix(domain, "[") {
+		return domain, nil
+	}
+	unicode, err := idna.Lookup.ToUnicode(domain)
+	if err != nil {
+		return "", fmt.Errorf("emailsupport: converting domain %q to Unicode: %w", domain, err)
+	}
+	return unicode, nil
+}
+
+// WithASCIIDomain returns a copy of the address with the domain converted by
+// DomainToASCII.  The local part is not changed: there is no ASCII-compatible
+// encoding for an internationalized local part.
+func (a Address) WithASCIIDomain() (Address, error) {
+	domain, err := DomainToASCII(a.Domain)
+	if err != nil {
+		return Address{}, err
+	}
+	a.Domain = domain
+	return a, nil
+}
+
+// WithUnicodeDomain returns a copy of the address with the domain converted
+// by DomainToUnicode.
+func (a Address) WithUnicodeDomain() (Address, error) {
+	domain, err := DomainToUnicode(a.Domain)
+	if err != nil {
+		return Address{}, err
+	}
+	a.Domain = domain
+	return a, nil
+}
@@ -0,0 +1,78 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestDomainIDNA(t *testing.T) {
+	for _, item := range []struct {
+		unicode string
+		ascii   string
+	}{
+		{"✉.example", "xn--4bi.example"}, // UTS-46 permits it; IDNA2008 does not
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"例子.广告", "xn--fsqu00a.xn--4rr70v"},
+		{"example.org", "example.org"},
+		{"[192.0.2.1]", "[192.0.2.1]"},
+		{"[IPv6:2001:db8::42]", "[IPv6:2001:db8::42]"},
+	} {
+		if got, err := DomainToASCII(item.unicode); err != nil || got != item.ascii {
+			t.Errorf("DomainToASCII(%q) gave %q, %v; expected %q", item.unicode, got, err, item.ascii)
+		}
+		if got, err := DomainToUnicode(item.ascii); err != nil || got != item.unicode {
+			t.Errorf("DomainToUnicode(%q) gave %q, %v; expected %q", item.ascii, got, err, item.unicode)
+		}
+		if ascii, _ := DomainToASCII(item.unicode); !EmailDomain.MatchString(ascii) {
+			t.Errorf("DomainToASCII(%q) gave %q, which is not an EmailDomain", item.unicode, ascii)
+		}
+	}
+
+	for _, item := range []struct {
+		in, ascii string
+	}{
+		{"Bücher.Example", "xn--bcher-kva.example"},
+		{"ＥＸＡＭＰＬＥ.org", "example.org"},
+		{"例子。广告", "xn--fsqu00a.xn--4rr70v"},
+	} {
+		if got, err := DomainToASCII(item.in); err != nil || got != item.ascii {
+			t.Errorf("DomainToASCII(%q) gave %q, %v; expected %q", item.in, got, err, item.ascii)
+		}
+	}
+
+	// the UTS-46 profile is more permissive than EmailDomainUTF8
+	if u, err := DomainToUnicode("xn--4bi.example"); err != nil || EmailDomainUTF8.MatchString(u) {
+		t.Errorf("DomainToUnicode(xn--4bi.example) gave %q, %v; expected a domain not matched by EmailDomainUTF8", u, err)
+	}
+
+	for _, bad := range []string{"a_b.example", "xn--zz.example", "-a.example"} {
+		if got, err := DomainToASCII(bad); err == nil {
+			t.Errorf("DomainToASCII(%q) gave %q, expected failure", bad, got)
+		}
+		if got, err := DomainToUnicode(bad); err == nil {
+			t.Errorf("DomainToUnicode(%q) gave %q, expected failure", bad, got)
+		}
+	}
+}
+
+func TestAddressIDNA(t *testing.T) {
+	a, err := ParseAddress("deliver@xn--4bi.example")
+	if err != nil {
+		t.Fatalf("ParseAddress failed: %v", err)
+	}
+	u, err := a.WithUnicodeDomain()
+	if err != nil {
+		t.Fatalf("WithUnicodeDomain failed: %v", err)
+	}
+	if u.String() != "deliver@✉.example" {
+		t.Errorf("WithUnicodeDomain gave %q", u.String())
+	}
+	back, err := u.WithASCIIDomain()
+	if err != nil {
+		t.Fatalf("WithASCIIDomain failed: %v", err)
+	}
+	if back != a {
+		t.Errorf("round-trip gave %#v, expected %#v", back, a)
+	}
+}
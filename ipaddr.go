@@ -0,0 +1,197 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// The IP address functions here first check the text with the same parser as
+// ValidateIPv4Address and friends, so accept exactly what the regexps accept
+// (which is stricter than net/netip, rejecting leading zeroes for instance),
+// and only then hand over to net/netip for the value.  Errors are always of
+// type *ParseError.
+
+// ValidateIPv4Netblock checks text against the `IPv4Netblock` grammar.
+func ValidateIPv4Netblock(text string) error {
+	p := defaultGrammar.newParser(text)
+	if err := p.ipv4Netblock(); err != nil {
+		return err
+	}
+	return p.finished("netblock")
+}
+
+// ValidateIPv6Netblock checks text against the `IPv6Netblock` grammar.
+func ValidateIPv6Netblock(text string) error {
+	p := defaultGrammar.newParser(text)
+	if err := p.ipv6Netblock(); err != nil {
+		return err
+	}
+	return p.finished("netblock")
+}
+
+// ValidateIPNetblock checks text against the `IPNetblock` grammar.
+func ValidateIPNetblock(text string) error {
+	// an IPv6 address always has a colon, an IPv4 address never does
+	if strings.IndexByte(text, ':') >= 0 {
+		return ValidateIPv6Netblock(text)
+	}
+	return ValidateIPv4Netblock(text)
+}
+
+// ValidateIPv6AddressScoped checks text against the `IPv6AddressScoped`
+// grammar.
+func ValidateIPv6AddressScoped(text string) error {
+	p := defaultGrammar.newParser(text)
+	if err := p.ipv6Address(); err != nil {
+		return err
+	}
+	if p.consume('%') {
+		start := p.pos
+		for c, ok := p.peek(); ok && isZoneIDChar(c); c, ok = p.peek() {
+			p.pos++
+		}
+		if p.pos == start {
+			return p.fail("ZoneID", start, "expected zone after '%', found "+p.describe(start))
+		}
+	}
+	return p.finished("IPv6-addr")
+}
+
+// ParseIPv4Address returns the address matched by `IPv4Address`.
+func ParseIPv4Address(text string) (netip.Addr, error) {
+	if err := ValidateIPv4Address(text); err != nil {
+		return netip.Addr{}, err
+	}
+	return parseAddr(text, "IPv4-address-literal")
+}
+
+// ParseIPv6Address returns the address matched by `IPv6Address`.  An address
+// written with a trailing IPv4 address, such as `::ffff:192.0.2.1`, is still
+// an IPv6 address; use Unmap if you want the IPv4 address.
+func ParseIPv6Address(text string) (netip.Addr, error) {
+	if err := ValidateIPv6Address(text); err != nil {
+		return netip.Addr{}, err
+	}
+	return parseAddr(text, "IPv6-addr")
+}
+
+// ParseIPv6AddressScoped returns the address matched by `IPv6AddressScoped`,
+// with the zone (if any) available from the Zone method.
+func ParseIPv6AddressScoped(text string) (netip.Addr, error) {
+	if err := ValidateIPv6AddressScoped(text); err != nil {
+		return netip.Addr{}, err
+	}
+	return parseAddr(text, "IPv6-addr")
+}
+
+// ParseIPv4Netblock returns the prefix matched by `IPv4Netblock`.  The prefix
+// holds the address as written; hostBits reports whether any bits are set
+// beyond the prefix length, as in `192.0.2.1/24`, which is usually a mistake
+// in an ACL.  Use the Masked method of the prefix to clear them.
+func ParseIPv4Netblock(text string) (prefix netip.Prefix, hostBits bool, err error) {
+	if err := ValidateIPv4Netblock(text); err != nil {
+		return netip.Prefix{}, false, err
+	}
+	return parsePrefix(text)
+}
+
+// ParseIPv6Netblock returns the prefix matched by `IPv6Netblock`; see
+// ParseIPv4Netblock.
+func ParseIPv6Netblock(text string) (prefix netip.Prefix, hostBits bool, err error) {
+	if err := ValidateIPv6Netblock(text); err != nil {
+		return netip.Prefix{}, false, err
+	}
+	return parsePrefix(text)
+}
+
+// ParseIPNetblock returns the prefix matched by `IPNetblock`; see
+// ParseIPv4Netblock.
+func ParseIPNetblock(text string) (prefix netip.Prefix, hostBits bool, err error) {
+	if err := ValidateIPNetblock(text); err != nil {
+		return netip.Prefix{}, false, err
+	}
+	return parsePrefix(text)
+}
+
+// parseAddr hands text which has been validated to net/netip, which should
+// not fail; if it does, the error is still a *ParseError, blaming the zone if
+// net/netip does.
+func parseAddr(text, production string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(text)
+	if err != nil {
+		offset := 0
+		if i := strings.IndexByte(text, '%'); i >= 0 && strings.Contains(err.Error(), "zone") {
+			production, offset = "ZoneID", i+1
+		}
+		return netip.Addr{}, &ParseError{Input: text, Production: production, Offset: offset, Reason: err.Error()}
+	}
+	return addr, nil
+}
+
+// parsePrefix is parseAddr for a netblock which has been validated.
+func parsePrefix(text string) (netip.Prefix, bool, error) {
+	slash := strings.IndexByte(text, '/')
+	if slash < 0 {
+		return netip.Prefix{}, false, &ParseError{Input: text, Production: "netblock", Offset: len(text),
+			Reason: "expected '/', found end of input"}
+	}
+	production := "IPv4-address-literal"
+	if strings.IndexByte(text[:slash], ':') >= 0 {
+		production = "IPv6-addr"
+	}
+	addr, err := netip.ParseAddr(text[:slash])
+	if err != nil {
+		return netip.Prefix{}, false, &ParseError{Input: text, Production: production, Offset: 0, Reason: err.Error()}
+	}
+	bits, err := strconv.Atoi(text[slash+1:])
+	prefix := netip.PrefixFrom(addr, bits)
+	if err != nil || !prefix.IsValid() {
+		return netip.Prefix{}, false, &ParseError{Input: text, Production: "prefix-length", Offset: slash + 1,
+			Reason: fmt.Sprintf("not a valid prefix length for %s", addr)}
+	}
+	return prefix, prefix.Masked() != prefix, nil
+}
+
+func (p *addrParser) ipv4Netblock() error {
+	if err := p.ipv4Address(); err != nil {
+		return err
+	}
+	return p.prefixLength(32)
+}
+
+func (p *addrParser) ipv6Netblock() error {
+	if err := p.ipv6Address(); err != nil {
+		return err
+	}
+	return p.prefixLength(128)
+}
+
+// prefixLength is the `/len` of a netblock: a decimal number without leading
+// zeroes, at most max.
+func (p *addrParser) prefixLength(max int) error {
+	if !p.consume('/') {
+		return p.fail("netblock", p.pos, "expected '/', found "+p.describe(p.pos))
+	}
+	start := p.pos
+	for c, ok := p.peek(); ok && isDigit(c) && p.pos-start < 4; c, ok = p.peek() {
+		p.pos++
+	}
+	switch value, _ := strconv.Atoi(p.in[start:p.pos]); {
+	case p.pos == start:
+		return p.fail("prefix-length", start, "expected digit, found "+p.describe(start))
+	case p.pos-start > 1 && p.in[start] == '0':
+		return p.fail("prefix-length", start, "leading zero not permitted")
+	case value > max:
+		return p.fail("prefix-length", start, "value exceeds "+strconv.Itoa(max))
+	}
+	return nil
+}
+
+// isZoneIDChar is the RFC 3986 unreserved set, as RFC 6874 uses for ZoneID
+func isZoneIDChar(c byte) bool {
+	return isLetDig(c) || c == '.' || c == '_' || c == '~' || c == '-'
+}
@@ -0,0 +1,151 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestParseIPAddresses(t *testing.T) {
+	for _, item := range []struct {
+		text string
+		v6   bool
+		want string
+	}{
+		{"192.0.2.1", false, "192.0.2.1"},
+		{"0.0.0.0", false, "0.0.0.0"},
+		{"2001:DB8::42", true, "2001:db8::42"},
+		{"::ffff:192.0.2.1", true, "::ffff:192.0.2.1"},
+		{"::", true, "::"},
+	} {
+		parse := ParseIPv4Address
+		if item.v6 {
+			parse = ParseIPv6Address
+		}
+		got, err := parse(item.text)
+		if err != nil {
+			t.Errorf("parsing %q failed: %v", item.text, err)
+			continue
+		}
+		if got != netip.MustParseAddr(item.want) {
+			t.Errorf("parsing %q gave %v, expected %s", item.text, got, item.want)
+		}
+	}
+	for _, bad := range []string{"192.0.2.01", "192.0.2", "::1", ""} {
+		if got, err := ParseIPv4Address(bad); err == nil {
+			t.Errorf("ParseIPv4Address(%q) accepted, giving %v", bad, got)
+		} else if _, ok := err.(*ParseError); !ok {
+			t.Errorf("ParseIPv4Address(%q) gave non-ParseError %T", bad, err)
+		}
+	}
+	for _, bad := range []string{"1::2::3", "192.0.2.1", "fe80::1%eth0", "::ffff:192.0.2.01"} {
+		if got, err := ParseIPv6Address(bad); err == nil {
+			t.Errorf("ParseIPv6Address(%q) accepted, giving %v", bad, got)
+		}
+	}
+}
+
+func TestParseIPv6AddressScoped(t *testing.T) {
+	addr, err := ParseIPv6AddressScoped("FE80::1%eth0")
+	if err != nil {
+		t.Fatalf("ParseIPv6AddressScoped failed: %v", err)
+	}
+	if addr.Zone() != "eth0" || addr.WithZone("") != netip.MustParseAddr("fe80::1") {
+		t.Errorf("ParseIPv6AddressScoped gave %v", addr)
+	}
+	_, err = ParseIPv6AddressScoped("fe80::1%")
+	if perr, ok := err.(*ParseError); !ok || perr.Production != "ZoneID" || perr.Offset != 8 {
+		t.Errorf("ParseIPv6AddressScoped with empty zone gave %v", err)
+	}
+	if _, err = ParseIPv6Address("fe80::1%eth0"); err == nil {
+		t.Errorf("ParseIPv6Address accepted a zone")
+	}
+}
+
+func TestParseIPNetblocks(t *testing.T) {
+	for _, item := range []struct {
+		text     string
+		want     string
+		hostBits bool
+	}{
+		{"192.0.2.0/24", "192.0.2.0/24", false},
+		{"192.0.2.1/24", "192.0.2.1/24", true},
+		{"192.0.2.1/32", "192.0.2.1/32", false},
+		{"0.0.0.0/0", "0.0.0.0/0", false},
+		{"2001:DB8::/32", "2001:db8::/32", false},
+		{"2001:db8::1/64", "2001:db8::1/64", true},
+		{"::/0", "::/0", false},
+	} {
+		got, hostBits, err := ParseIPNetblock(item.text)
+		if err != nil {
+			t.Errorf("ParseIPNetblock(%q) failed: %v", item.text, err)
+			continue
+		}
+		if got != netip.MustParsePrefix(item.want) || hostBits != item.hostBits {
+			t.Errorf("ParseIPNetblock(%q) gave %v, %v; expected %s, %v", item.text, got, hostBits, item.want, item.hostBits)
+		}
+	}
+	if _, _, err := ParseIPv4Netblock("2001:db8::/32"); err == nil {
+		t.Errorf("ParseIPv4Netblock accepted an IPv6 netblock")
+	}
+	if _, _, err := ParseIPv6Netblock("192.0.2.0/24"); err == nil {
+		t.Errorf("ParseIPv6Netblock accepted an IPv4 netblock")
+	}
+	for _, item := range []struct {
+		text       string
+		production string
+		offset     int
+	}{
+		{"192.0.2.0", "netblock", 9},
+		{"192.0.2.0/", "prefix-length", 10},
+		{"192.0.2.0/08", "prefix-length", 10},
+		{"192.0.2.0/33", "prefix-length", 10},
+		{"192.0.2.0/24/", "netblock", 12},
+		{"2001:db8::/129", "prefix-length", 11},
+		{"2001:db8::/1280", "prefix-length", 11},
+	} {
+		_, _, err := ParseIPNetblock(item.text)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseIPNetblock(%q) gave %v, expected a *ParseError", item.text, err)
+			continue
+		}
+		if perr.Production != item.production || perr.Offset != item.offset {
+			t.Errorf("ParseIPNetblock(%q) failed in %s at %d, expected %s at %d",
+				item.text, perr.Production, perr.Offset, item.production, item.offset)
+		}
+	}
+}
+
+// The parser should reject anything which net/netip would, but if net/netip
+// does fail, the error must still be a *ParseError.
+func TestNetipErrorsWrapped(t *testing.T) {
+	for _, item := range []struct {
+		name       string
+		err        error
+		production string
+		offset     int
+	}{
+		{"public bad prefix length", errorOf3(ParseIPNetblock("192.0.2.0/33")), "prefix-length", 10},
+		{"public bad zone", errorOf2(ParseIPv6AddressScoped("fe80::1%")), "ZoneID", 8},
+		{"netip bad prefix length", errorOf3(parsePrefix("192.0.2.0/33")), "prefix-length", 10},
+		{"netip bad prefix address", errorOf3(parsePrefix("192.0.2/24")), "IPv4-address-literal", 0},
+		{"netip bad zone", errorOf2(parseAddr("fe80::1%", "IPv6-addr")), "ZoneID", 8},
+		{"netip bad address", errorOf2(parseAddr("1::2::3", "IPv6-addr")), "IPv6-addr", 0},
+	} {
+		var perr *ParseError
+		if !errors.As(item.err, &perr) {
+			t.Errorf("%s: gave %v, expected a *ParseError", item.name, item.err)
+			continue
+		}
+		if perr.Production != item.production || perr.Offset != item.offset {
+			t.Errorf("%s: failed in %s at %d, expected %s at %d",
+				item.name, perr.Production, perr.Offset, item.production, item.offset)
+		}
+	}
+}
+
+func errorOf2(_ netip.Addr, err error) error           { return err }
+func errorOf3(_ netip.Prefix, _ bool, err error) error { return err }
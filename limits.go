@@ -0,0 +1,107 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RFC 5321 section 4.5.3.1 sets size limits on the parts of an address.  The
+// grammar alone permits any length, and so do the regular expressions; these
+// limits are enforced by the Strict forms.  The path limit of 256 octets
+// includes the angle brackets, so leaves 254 octets for the address itself.
+// The label limit is from RFC 1035 and applies only to domain names, not to
+// address-literals.
+const (
+	MaxLocalPartLength = 64
+	MaxDomainLength    = 255
+	MaxLabelLength     = 63
+	MaxAddressLength   = 254
+)
+
+// LengthError reports that part of an address exceeds its limit.  The Limit is
+// one of "local-part", "domain", "label" or "path".
+type LengthError struct {
+	Limit  string
+	Max    int
+	Length int
+	Text   string
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("emailsupport: %s %q is %d octets long, exceeding the limit of %d",
+		e.Limit, e.Text, e.Length, e.Max)
+}
+
+// CheckLengths returns a *LengthError if any part of the address exceeds the
+// RFC5321 limits, measured with the local part quoted as String() would.
+func (a Address) CheckLengths() error {
+	return checkLengths(a.localPartString(), a.Domain, a.AddressLiteral)
+}
+
+func checkLengths(localPart, domain string, literal bool) error {
+	if len(localPart) > MaxLocalPartLength {
+		return &LengthError{Limit: "local-part", Max: MaxLocalPartLength, Length: len(localPart), Text: localPart}
+	}
+	if len(domain) > MaxDomainLength {
+		return &LengthError{Limit: "domain", Max: MaxDomainLength, Length: len(domain), Text: domain}
+	}
+	if !literal {
+		for _, label := range strings.Split(domain, ".") {
+			if len(label) > MaxLabelLength {
+				return &LengthError{Limit: "label", Max: MaxLabelLength, Length: len(label), Text: label}
+			}
+		}
+	}
+	if total := len(localPart) + 1 + len(domain); total > MaxAddressLength {
+		return &LengthError{Limit: "path", Max: MaxAddressLength, Length: total, Text: localPart + "@" + domain}
+	}
+	return nil
+}
+
+// ValidateEmailAddressStrict is ValidateEmailAddress, also enforcing the
+// length limits; the error will be a *ParseError or a *LengthError.
+func ValidateEmailAddressStrict(text string) error {
+	return defaultGrammar.ValidateEmailAddressStrict(text)
+}
+
+// ValidateEmailAddressStrict is the package-level function, using this
+// grammar.
+func (g *Grammar) ValidateEmailAddressStrict(text string) error {
+	a, err := g.ParseAddress(text)
+	if err != nil {
+		return err
+	}
+	// measure the local part as written, not as we would re-quote it
+	return checkLengths(text[:len(text)-len(a.Domain)-1], a.Domain, a.AddressLiteral)
+}
+
+// StrictMatcher checks addresses against a grammar and the length limits.
+// Those limits can not be expressed in a Go regexp, so there is no `Txt` form
+// of the pattern, but a StrictMatcher offers MatchString so that it can often
+// be used in place of a *regexp.Regexp.  It is always anchored.  Get one from
+// the EmailAddressStrict method of a Grammar; the zero value uses the
+// DefaultGrammar.
+type StrictMatcher struct {
+	grammar *Grammar
+}
+
+// MatchString reports whether text is an address within the length limits.
+func (m *StrictMatcher) MatchString(text string) bool {
+	return m.Validate(text) == nil
+}
+
+// Validate returns nil if text is an address within the length limits, else a
+// *ParseError or *LengthError explaining why not.
+func (m *StrictMatcher) Validate(text string) error {
+	if m.grammar == nil {
+		return defaultGrammar.ValidateEmailAddressStrict(text)
+	}
+	return m.grammar.ValidateEmailAddressStrict(text)
+}
+
+// EmailAddressStrict is `EmailAddress` with the RFC5321 section 4.5.3.1
+// length limits also enforced: 64 octets for the local part, 255 for the
+// domain, 63 for each label of a domain name, and 254 for the whole address.
+var EmailAddressStrict = defaultGrammar.emailAddressStrict
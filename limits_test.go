@@ -0,0 +1,79 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmailAddressStrict(t *testing.T) {
+	label63 := strings.Repeat("a", 63)
+	// 4 labels of 63, plus 3 dots, gives 255
+	domain255 := strings.Join([]string{label63, label63, label63, label63}, ".")
+	label50 := strings.Repeat("a", 50)
+	domain256 := strings.Join([]string{label50, label50, label50, label50, label50, "a"}, ".")
+
+	for _, item := range []struct {
+		text  string
+		limit string // empty for success
+	}{
+		{"john@example.org", ""},
+		{strings.Repeat("a", 64) + "@example.org", ""},
+		{strings.Repeat("a", 65) + "@example.org", "local-part"},
+		{`"` + strings.Repeat("a", 62) + `"@example.org`, ""},
+		{`"` + strings.Repeat("a", 63) + `"@example.org`, "local-part"},
+		{`"\` + strings.Repeat("a", 62) + `"@example.org`, "local-part"}, // measured as written
+		{"john@" + label63 + ".example", ""},
+		{"john@" + label63 + "a.example", "label"},
+		{"j@" + domain255[:252], ""},
+		{"jo@" + domain255[:252], "path"},
+		{"j@" + domain256, "domain"},
+		{"john@[IPv6:2001:db8::42]", ""},
+		{strings.Repeat("a", 64) + "@" + domain255[:189], ""},
+		{strings.Repeat("a", 64) + "@" + domain255[:190], "path"},
+	} {
+		err := ValidateEmailAddressStrict(item.text)
+		if EmailAddressStrict.MatchString(item.text) != (err == nil) {
+			t.Errorf("EmailAddressStrict.MatchString(%q) disagrees with ValidateEmailAddressStrict", item.text)
+		}
+		if err == nil {
+			if item.limit != "" {
+				t.Errorf("ValidateEmailAddressStrict(%q) succeeded, expected %s limit", item.text, item.limit)
+			}
+			continue
+		}
+		le, ok := err.(*LengthError)
+		switch {
+		case !ok:
+			t.Errorf("ValidateEmailAddressStrict(%q) failed: %v", item.text, err)
+		case le.Limit != item.limit:
+			t.Errorf("ValidateEmailAddressStrict(%q) exceeded %s limit, expected %q: %v", item.text, le.Limit, item.limit, err)
+		}
+	}
+
+	iterateBoolPatternMatch(t, EmailAddress, "EmailAddress", []boolPatternMatch{
+		{strings.Repeat("a", 65) + "@example.org", true},
+	})
+	if EmailAddressStrict.MatchString("not an address") {
+		t.Errorf("EmailAddressStrict accepted a non-address")
+	}
+	if _, ok := ValidateEmailAddressStrict("john@").(*ParseError); !ok {
+		t.Errorf("ValidateEmailAddressStrict did not give a ParseError for a bad address")
+	}
+	var zero StrictMatcher
+	if !zero.MatchString("john@example.org") || zero.MatchString(strings.Repeat("a", 65)+"@example.org") {
+		t.Errorf("the zero StrictMatcher does not use the DefaultGrammar with the length limits")
+	}
+}
+
+func TestAddressCheckLengths(t *testing.T) {
+	a := Address{LocalPart: strings.Repeat("a", 63), Domain: "example.org", QuotedLocalPart: true}
+	if err := a.CheckLengths(); err == nil {
+		t.Errorf("CheckLengths did not count the quotes")
+	}
+	a.QuotedLocalPart = false
+	if err := a.CheckLengths(); err != nil {
+		t.Errorf("CheckLengths failed: %v", err)
+	}
+}
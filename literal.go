@@ -0,0 +1,144 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RFC 5321 defines the General-address-literal, `[tag:content]`, as a hook
+// for future forms of address-literal, with the tag registered with IANA.  At
+// the time of writing, the only registered tag is "IPv6", which is handled as
+// a core part of the grammar.  Rather than guess at the future, callers which
+// know of a tag can register it here, with a function to validate the content.
+//
+// Tags are compared case-insensitively, as is done for "IPv6".
+
+// AddressLiteralValidator checks the content of a General-address-literal,
+// being the dcontent after the colon.  It should return nil if the content is
+// acceptable.
+type AddressLiteralValidator func(content string) error
+
+var addressLiteralTags struct {
+	sync.RWMutex
+	validators map[string]AddressLiteralValidator
+}
+
+// RegisterAddressLiteralTag makes a tag for a General-address-literal known,
+// so that addresses using it are accepted by ValidateGeneralAddressLiteral and
+// by ParseAddressWithGeneralLiteral.  A nil validate accepts any content which
+// is syntactically valid.  It is an error to register "IPv6", a tag which is
+// not a valid Standardized-tag, or a tag which is already registered.
+func RegisterAddressLiteralTag(tag string, validate AddressLiteralValidator) error {
+	if !isStandardizedTag(tag) {
+		return fmt.Errorf("emailsupport: %q is not a valid Standardized-tag", tag)
+	}
+	key := strings.ToLower(tag)
+	if key == "ipv6" {
+		return fmt.Errorf("emailsupport: the %q address-literal tag is built in", tag)
+	}
+	addressLiteralTags.Lock()
+	defer addressLiteralTags.Unlock()
+	if _, ok := addressLiteralTags.validators[key]; ok {
+		return fmt.Errorf("emailsupport: address-literal tag %q already registered", tag)
+	}
+	if addressLiteralTags.validators == nil {
+		addressLiteralTags.validators = make(map[string]AddressLiteralValidator)
+	}
+	if validate == nil {
+		validate = func(string) error { return nil }
+	}
+	addressLiteralTags.validators[key] = validate
+	return nil
+}
+
+// UnregisterAddressLiteralTag removes a tag registered with
+// RegisterAddressLiteralTag; it is not an error if the tag is not registered.
+func UnregisterAddressLiteralTag(tag string) {
+	addressLiteralTags.Lock()
+	defer addressLiteralTags.Unlock()
+	delete(addressLiteralTags.validators, strings.ToLower(tag))
+}
+
+func addressLiteralValidator(tag string) (AddressLiteralValidator, bool) {
+	if strings.EqualFold(tag, "IPv6") {
+		return ValidateIPv6Address, true
+	}
+	addressLiteralTags.RLock()
+	defer addressLiteralTags.RUnlock()
+	validate, ok := addressLiteralTags.validators[strings.ToLower(tag)]
+	return validate, ok
+}
+
+// ValidateGeneralAddressLiteral checks a General-address-literal, with or
+// without the surrounding square brackets, against the registered tags.
+// Errors are of type *ParseError.
+func ValidateGeneralAddressLiteral(literal string) error {
+	p := defaultGrammar.newParser(literal)
+	bracketed := p.consume('[')
+	if err := p.generalAddressLiteral(); err != nil {
+		return err
+	}
+	if bracketed && !p.consume(']') {
+		return p.fail("address-literal", p.pos, "expected ']', found "+p.describe(p.pos))
+	}
+	return p.finished("General-address-literal")
+}
+
+// ParseAddressWithGeneralLiteral is ParseAddress, but also accepting a
+// General-address-literal if the tag has been registered with
+// RegisterAddressLiteralTag.
+func ParseAddressWithGeneralLiteral(s string) (Address, error) {
+	p := defaultGrammar.newParser(s)
+	p.generalLiterals = true
+	return p.address()
+}
+
+func isStandardizedTag(tag string) bool {
+	if tag == "" || !isLetDig(tag[len(tag)-1]) {
+		return false
+	}
+	for i := 0; i < len(tag); i++ {
+		if !isLetDig(tag[i]) && tag[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDContent(c byte) bool {
+	return c >= 33 && c <= 90 || c >= 94 && c <= 126
+}
+
+// generalAddressLiteral parses `tag:content` (without brackets) up to the
+// end of input or a ']'.
+func (p *addrParser) generalAddressLiteral() error {
+	start := p.pos
+	for c, ok := p.peek(); ok && (isLetDig(c) || c == '-'); c, ok = p.peek() {
+		p.pos++
+	}
+	tag := p.in[start:p.pos]
+	if !isStandardizedTag(tag) {
+		return p.fail("Standardized-tag", start, "expected letters, digits and hyphens, not ending with hyphen")
+	}
+	if !p.consume(':') {
+		return p.fail("General-address-literal", p.pos, "expected ':' after tag, found "+p.describe(p.pos))
+	}
+	contentStart := p.pos
+	for c, ok := p.peek(); ok && isDContent(c); c, ok = p.peek() {
+		p.pos++
+	}
+	if p.pos == contentStart {
+		return p.fail("dcontent", p.pos, "expected content after tag, found "+p.describe(p.pos))
+	}
+	validate, ok := addressLiteralValidator(tag)
+	if !ok {
+		return p.fail("Standardized-tag", start, fmt.Sprintf("tag %q is not registered", tag))
+	}
+	if err := validate(p.in[contentStart:p.pos]); err != nil {
+		return p.fail("General-address-literal", contentStart, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddressLiteralRegistry(t *testing.T) {
+	errNotUpper := errors.New("content must be upper-case")
+	if err := RegisterAddressLiteralTag("X-Test", func(content string) error {
+		if strings.ToUpper(content) != content {
+			return errNotUpper
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("RegisterAddressLiteralTag failed: %v", err)
+	}
+	defer UnregisterAddressLiteralTag("x-test")
+
+	for _, bad := range []string{"X-Test", "x-test", "IPv6", "ipv6", "foo-", "", "a_b"} {
+		if err := RegisterAddressLiteralTag(bad, nil); err == nil {
+			t.Errorf("RegisterAddressLiteralTag(%q) succeeded, expected failure", bad)
+			UnregisterAddressLiteralTag(bad)
+		}
+	}
+
+	for _, item := range []struct {
+		text       string
+		production string // empty for success
+	}{
+		{"[x-test:ABC]", ""},
+		{"X-TEST:ABC", ""},
+		{"[IPv6:2001:db8::42]", ""},
+		{"[x-test:abc]", "General-address-literal"},
+		{"[x-other:ABC]", "Standardized-tag"},
+		{"[x-test:]", "dcontent"},
+		{"[x-test:ABC", "address-literal"},
+		{"[IPv6:2001:db8::42::1]", "General-address-literal"},
+	} {
+		err := ValidateGeneralAddressLiteral(item.text)
+		switch {
+		case item.production == "" && err != nil:
+			t.Errorf("ValidateGeneralAddressLiteral(%q) failed: %v", item.text, err)
+		case item.production != "" && err == nil:
+			t.Errorf("ValidateGeneralAddressLiteral(%q) succeeded, expected failure", item.text)
+		case item.production != "" && err.(*ParseError).Production != item.production:
+			t.Errorf("ValidateGeneralAddressLiteral(%q) failed in the wrong production: %v", item.text, err)
+		}
+	}
+
+	a, err := ParseAddressWithGeneralLiteral("john@[x-test:ABC]")
+	if err != nil {
+		t.Fatalf("ParseAddressWithGeneralLiteral failed: %v", err)
+	}
+	if expect := (Address{LocalPart: "john", Domain: "[x-test:ABC]", AddressLiteral: true}); a != expect {
+		t.Errorf("ParseAddressWithGeneralLiteral gave %#v, expected %#v", a, expect)
+	}
+	if _, err := ParseAddress("john@[x-test:ABC]"); err == nil {
+		t.Errorf("ParseAddress accepted a General-address-literal")
+	}
+	for _, bad := range []string{"john@[x-test:abc]", "john@[x-other:ABC]", "john@[x-test:ABC]x"} {
+		if _, err := ParseAddressWithGeneralLiteral(bad); err == nil {
+			t.Errorf("ParseAddressWithGeneralLiteral(%q) succeeded, expected failure", bad)
+		}
+	}
+	if _, err := ParseAddressWithGeneralLiteral("john@[IPv6:2001:db8::42]"); err != nil {
+		t.Errorf("ParseAddressWithGeneralLiteral failed on IPv6: %v", err)
+	}
+}
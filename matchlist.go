@@ -0,0 +1,604 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// Exim configures its ACLs with lists of items separated by colons, each of
+// which may be negated with `!`; the first item to match decides the answer.
+// There are three kinds, for domains, addresses and hosts, with different
+// items, and a list of one kind may refer to a named list of the same kind as
+// `+name`.  We handle the items which can be checked without DNS or other
+// lookups:
+//
+//   - in all lists: `*` to match anything, `+name` for a named list
+//   - domains: a domain (or address-literal) exactly; `*.example.org` or
+//     `*example.org` for a suffix; `^regex`; `@` for the PrimaryHostname; and
+//     `@[]` for an address-literal of any of the LocalAddresses
+//   - addresses: `^regex` against the whole address; `local@domain`, where
+//     the local part may be `*` or start with `*` for a suffix, and the domain
+//     is any item of a domain list, as in `*@+local_domains`; and without an
+//     `@`, any item of a domain list, matched against the domain
+//   - hosts: an address or netblock, as for ParseNetblockEntry; `@[]` for any
+//     of the LocalAddresses; and the domain list items `@`, `^regex`, a suffix
+//     or an exact name, matched against the host name if there is one
+//
+// As in Exim, domains and host names are compared without regard to case, and
+// so are local parts, as RFC2505 recommends; regular expressions match
+// without regard to case too.  If no item matches, the answer is no, unless
+// the last item was negated: then the list is taken to end with `*`, so that
+// `!+local_domains` matches all other domains.  The separator can be changed
+// from a colon by starting the list with `<` and the new separator, as in
+// `<; 2001:db8::/32; 192.0.2.0/24`; otherwise a separator within an item is
+// doubled, as in `2001::db8::::/32`.
+
+// NamedLists holds named match lists, for reference as `+name` by the lists
+// parsed with it, and what `@` and `@[]` refer to.  The zero value is ready to
+// use, and checks local parts with the DefaultGrammar unless Grammar is set.
+// The PrimaryHostname and LocalAddresses are used when matching, not when
+// parsing, so may be set later.  Lists may be matched concurrently, but not
+// while new lists are being defined.
+type NamedLists struct {
+	Grammar         *Grammar
+	PrimaryHostname string
+	LocalAddresses  []netip.Addr
+
+	lists map[string]*matchList
+}
+
+// DomainMatchList is an Exim-style domain list.
+type DomainMatchList struct{ list *matchList }
+
+// AddressMatchList is an Exim-style address list.
+type AddressMatchList struct{ list *matchList }
+
+// HostMatchList is an Exim-style host list.
+type HostMatchList struct{ list *matchList }
+
+// MatchStep is one item on the way to the answer from a match list: the
+// item, as written, and its position in the list from 1.  The List is the
+// name of a named list, or empty for the list being matched.  A Position of 0
+// means that no item of the list matched, but the last was negated.
+type MatchStep struct {
+	List     string
+	Position int
+	Item     string
+}
+
+// MatchResult is the answer from a match list, with the Steps which explain
+// it: the item which decided the answer and, where that item is a named
+// list, the item within that which matched, and so on.  If no item matched,
+// Steps is empty.
+type MatchResult struct {
+	Matched bool
+	Steps   []MatchStep
+}
+
+func (r MatchResult) String() string {
+	var b strings.Builder
+	if r.Matched {
+		b.WriteString("matched")
+	} else {
+		b.WriteString("not matched")
+	}
+	if len(r.Steps) == 0 {
+		b.WriteString(": no item matched")
+		return b.String()
+	}
+	for i, step := range r.Steps {
+		if i == 0 {
+			b.WriteString(" by ")
+		} else {
+			b.WriteString(", through ")
+		}
+		if step.Position == 0 {
+			b.WriteString("reaching the end of ")
+			if step.List != "" {
+				b.WriteString("+" + step.List)
+			} else {
+				b.WriteString("the list")
+			}
+			b.WriteString(" after a negated item")
+			continue
+		}
+		fmt.Fprintf(&b, "item %d %q", step.Position, step.Item)
+		if step.List != "" {
+			b.WriteString(" of +" + step.List)
+		}
+	}
+	return b.String()
+}
+
+type listKind int
+
+const (
+	domainListKind listKind = iota
+	addressListKind
+	hostListKind
+)
+
+func (k listKind) String() string {
+	switch k {
+	case domainListKind:
+		return "domain"
+	case addressListKind:
+		return "address"
+	case hostListKind:
+		return "host"
+	}
+	return fmt.Sprintf("listKind(%d)", int(k))
+}
+
+// matchSubject is what is being matched; the domain and host are lower-cased
+// and the address is in canonical form.
+type matchSubject struct {
+	domain    string
+	localPart string
+	address   string
+	ip        netip.Addr
+	host      string
+}
+
+// itemTest reports whether an item matches, and the steps within any named
+// list which it refers to.
+type itemTest func(s *matchSubject) (bool, []MatchStep)
+
+type matchItem struct {
+	text    string
+	negated bool
+	test    itemTest
+}
+
+type matchList struct {
+	name  string
+	items []matchItem
+}
+
+func (l *matchList) match(s *matchSubject) MatchResult {
+	for i, item := range l.items {
+		ok, inner := item.test(s)
+		if !ok {
+			continue
+		}
+		steps := append([]MatchStep{{List: l.name, Position: i + 1, Item: item.text}}, inner...)
+		return MatchResult{Matched: !item.negated, Steps: steps}
+	}
+	if n := len(l.items); n > 0 && l.items[n-1].negated {
+		return MatchResult{Matched: true, Steps: []MatchStep{{List: l.name}}}
+	}
+	return MatchResult{}
+}
+
+// ParseDomainMatchList parses a domain list, using a zero NamedLists.
+func ParseDomainMatchList(list string) (*DomainMatchList, error) {
+	return (&NamedLists{}).ParseDomainMatchList(list)
+}
+
+// ParseAddressMatchList parses an address list, using a zero NamedLists.
+func ParseAddressMatchList(list string) (*AddressMatchList, error) {
+	return (&NamedLists{}).ParseAddressMatchList(list)
+}
+
+// ParseHostMatchList parses a host list, using a zero NamedLists.
+func ParseHostMatchList(list string) (*HostMatchList, error) {
+	return (&NamedLists{}).ParseHostMatchList(list)
+}
+
+// ParseDomainMatchList parses a domain list, which may refer to the named
+// domain lists defined so far.
+func (n *NamedLists) ParseDomainMatchList(list string) (*DomainMatchList, error) {
+	l, err := n.parse(domainListKind, "", list)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainMatchList{l}, nil
+}
+
+// ParseAddressMatchList parses an address list, which may refer to the named
+// address lists defined so far, and to named domain lists in the domain of
+// an item.
+func (n *NamedLists) ParseAddressMatchList(list string) (*AddressMatchList, error) {
+	l, err := n.parse(addressListKind, "", list)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressMatchList{l}, nil
+}
+
+// ParseHostMatchList parses a host list, which may refer to the named host
+// lists defined so far.
+func (n *NamedLists) ParseHostMatchList(list string) (*HostMatchList, error) {
+	l, err := n.parse(hostListKind, "", list)
+	if err != nil {
+		return nil, err
+	}
+	return &HostMatchList{l}, nil
+}
+
+// DefineDomainList parses a domain list and names it, for later lists to
+// refer to as `+name`.  Each kind of list has its own names, and a name can
+// only be defined once.
+func (n *NamedLists) DefineDomainList(name, list string) error {
+	return n.define(domainListKind, name, list)
+}
+
+// DefineAddressList parses and names an address list; see DefineDomainList.
+func (n *NamedLists) DefineAddressList(name, list string) error {
+	return n.define(addressListKind, name, list)
+}
+
+// DefineHostList parses and names a host list; see DefineDomainList.
+func (n *NamedLists) DefineHostList(name, list string) error {
+	return n.define(hostListKind, name, list)
+}
+
+func (n *NamedLists) define(kind listKind, name, list string) error {
+	if name == "" || strings.ContainsAny(name, " \t:;+!") {
+		return fmt.Errorf("emailsupport: invalid %s list name %q", kind, name)
+	}
+	if n.lookup(kind, name) != nil {
+		return fmt.Errorf("emailsupport: %s list %q is already defined", kind, name)
+	}
+	l, err := n.parse(kind, name, list)
+	if err != nil {
+		return err
+	}
+	if n.lists == nil {
+		n.lists = make(map[string]*matchList)
+	}
+	n.lists[kind.String()+" "+name] = l
+	return nil
+}
+
+func (n *NamedLists) lookup(kind listKind, name string) *matchList {
+	return n.lists[kind.String()+" "+name]
+}
+
+func (n *NamedLists) grammar() *Grammar {
+	if n.Grammar != nil {
+		return n.Grammar
+	}
+	return defaultGrammar
+}
+
+func (n *NamedLists) parse(kind listKind, name, list string) (*matchList, error) {
+	l := &matchList{name: name}
+	for i, text := range splitMatchList(list) {
+		item := matchItem{text: text}
+		pattern := text
+		if strings.HasPrefix(pattern, "!") {
+			item.negated = true
+			pattern = strings.TrimLeft(pattern[1:], " \t")
+		}
+		var err error
+		switch kind {
+		case domainListKind:
+			item.test, err = n.domainItem(pattern)
+		case addressListKind:
+			item.test, err = n.addressItem(pattern)
+		case hostListKind:
+			item.test, err = n.hostItem(pattern)
+		}
+		if err != nil {
+			where := kind.String() + " list"
+			if name != "" {
+				where += " +" + name
+			}
+			return nil, fmt.Errorf("emailsupport: %s item %d %q: %w", where, i+1, text, err)
+		}
+		l.items = append(l.items, item)
+	}
+	return l, nil
+}
+
+// splitMatchList splits a list at its separators, handling a leading `<` to
+// change the separator and a doubled separator for a literal one.  Items are
+// trimmed of white-space, and empty items are dropped.
+func splitMatchList(list string) []string {
+	list = strings.TrimSpace(list)
+	sep := byte(':')
+	if len(list) >= 2 && list[0] == '<' && list[1] > ' ' && list[1] < 0x7F && !isLetDig(list[1]) {
+		sep = list[1]
+		list = list[2:]
+	}
+	var items []string
+	var b strings.Builder
+	add := func() {
+		if item := strings.TrimSpace(b.String()); item != "" {
+			items = append(items, item)
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(list); i++ {
+		switch {
+		case list[i] != sep:
+			b.WriteByte(list[i])
+		case i+1 < len(list) && list[i+1] == sep:
+			b.WriteByte(sep)
+			i++
+		default:
+			add()
+		}
+	}
+	add()
+	return items
+}
+
+// namedItem refers to a named list; its answer is whether that list matched.
+func (n *NamedLists) namedItem(kind listKind, name string, subject func(*matchSubject) *matchSubject) (itemTest, error) {
+	named := n.lookup(kind, name)
+	if named == nil {
+		return nil, fmt.Errorf("no %s list named %q", kind, name)
+	}
+	return func(s *matchSubject) (bool, []MatchStep) {
+		r := named.match(subject(s))
+		return r.Matched, r.Steps
+	}, nil
+}
+
+func sameSubject(s *matchSubject) *matchSubject { return s }
+
+func (n *NamedLists) domainItem(pattern string) (itemTest, error) {
+	if strings.HasPrefix(pattern, "+") {
+		return n.namedItem(domainListKind, pattern[1:], sameSubject)
+	}
+	return n.domainPattern(pattern, func(s *matchSubject) string { return s.domain })
+}
+
+// domainPattern handles the items which are common to domain lists and host
+// lists, and the domain of an address list item, matching against the domain
+// or host name which get returns.
+func (n *NamedLists) domainPattern(pattern string, get func(*matchSubject) string) (itemTest, error) {
+	switch {
+	case pattern == "":
+		return nil, fmt.Errorf("empty item")
+	case pattern == "*":
+		return func(*matchSubject) (bool, []MatchStep) { return true, nil }, nil
+	case pattern == "@":
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return n.PrimaryHostname != "" && get(s) == matchDomain(n.PrimaryHostname), nil
+		}, nil
+	case pattern == "@[]":
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return n.isLocalAddress(literalAddr(get(s))), nil
+		}, nil
+	case strings.HasPrefix(pattern, "^"):
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return get(s) != "" && re.MatchString(get(s)), nil
+		}, nil
+	case strings.HasPrefix(pattern, "*"):
+		suffix := strings.ToLower(pattern[1:])
+		if err := validateItemDomain(strings.TrimPrefix(suffix, ".")); err != nil {
+			return nil, err
+		}
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return strings.HasSuffix(get(s), suffix), nil
+		}, nil
+	case strings.ContainsAny(pattern, ";$"):
+		return nil, fmt.Errorf("lookups and expansions are not supported")
+	}
+	if err := validateItemDomain(pattern); err != nil {
+		return nil, err
+	}
+	domain := matchDomain(pattern)
+	return func(s *matchSubject) (bool, []MatchStep) {
+		return get(s) == domain, nil
+	}, nil
+}
+
+// validateItemDomain checks a domain in a list item label by label; unlike
+// `EmailDomain`, it permits a single label, as in `localhost` or `*.org`.
+func validateItemDomain(text string) error {
+	p := defaultGrammar.newParser(text)
+	if c, ok := p.peek(); ok && c == '[' {
+		if _, err := p.addressLiteral(); err != nil {
+			return err
+		}
+		return p.finished("Domain")
+	}
+	for {
+		if err := p.subDomain(); err != nil {
+			return err
+		}
+		if !p.consume('.') {
+			break
+		}
+	}
+	return p.finished("Domain")
+}
+
+func (n *NamedLists) addressItem(pattern string) (itemTest, error) {
+	if strings.HasPrefix(pattern, "+") {
+		return n.namedItem(addressListKind, pattern[1:], sameSubject)
+	}
+	if strings.HasPrefix(pattern, "^") {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return re.MatchString(s.address), nil
+		}, nil
+	}
+	if pattern == "@" || pattern == "@[]" || strings.IndexByte(pattern, '@') < 0 {
+		return n.domainItem(pattern)
+	}
+	local, domainPattern := splitAddressPattern(pattern)
+	var domainTest itemTest
+	var err error
+	if strings.HasPrefix(domainPattern, "+") {
+		domainTest, err = n.namedItem(domainListKind, domainPattern[1:], func(s *matchSubject) *matchSubject {
+			return &matchSubject{domain: s.domain}
+		})
+	} else {
+		domainTest, err = n.domainPattern(domainPattern, func(s *matchSubject) string { return s.domain })
+	}
+	if err != nil {
+		return nil, err
+	}
+	localTest, err := n.localPartPattern(local)
+	if err != nil {
+		return nil, err
+	}
+	return func(s *matchSubject) (bool, []MatchStep) {
+		if !localTest(s.localPart) {
+			return false, nil
+		}
+		return domainTest(s)
+	}, nil
+}
+
+// splitAddressPattern splits an item at the `@` before its domain, which may
+// itself be `@` or `@[]`.
+func splitAddressPattern(pattern string) (string, string) {
+	for _, domain := range []string{"@[]", "@"} {
+		if strings.HasSuffix(pattern, "@"+domain) {
+			return pattern[:len(pattern)-len(domain)-1], domain
+		}
+	}
+	at := strings.LastIndexByte(pattern, '@')
+	return pattern[:at], pattern[at+1:]
+}
+
+// localPartPattern handles `*`, a suffix after `*`, or a local part which is
+// matched by `EmailLHS`.
+func (n *NamedLists) localPartPattern(pattern string) (func(string) bool, error) {
+	switch {
+	case pattern == "":
+		return nil, fmt.Errorf("empty local part")
+	case pattern == "*":
+		return func(string) bool { return true }, nil
+	case strings.HasPrefix(pattern, "*"):
+		suffix := strings.ToLower(pattern[1:])
+		return func(local string) bool {
+			return strings.HasSuffix(strings.ToLower(local), suffix)
+		}, nil
+	}
+	p := n.grammar().newParser(pattern)
+	local, _, err := p.localPart()
+	if err == nil {
+		err = p.finished("Local-part")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(l string) bool { return strings.EqualFold(l, local) }, nil
+}
+
+func (n *NamedLists) hostItem(pattern string) (itemTest, error) {
+	switch {
+	case strings.HasPrefix(pattern, "+"):
+		return n.namedItem(hostListKind, pattern[1:], sameSubject)
+	case pattern == "@[]":
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return n.isLocalAddress(s.ip), nil
+		}, nil
+	case strings.HasPrefix(pattern, "^"), strings.HasPrefix(pattern, "*"), pattern == "@":
+		return n.domainPattern(pattern, func(s *matchSubject) string { return s.host })
+	}
+	if looksLikeIP(pattern) {
+		entry, err := ParseNetblockEntry(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *matchSubject) (bool, []MatchStep) {
+			return s.ip.IsValid() && entry.Prefix.Contains(s.ip), nil
+		}, nil
+	}
+	return n.domainPattern(pattern, func(s *matchSubject) string { return s.host })
+}
+
+// looksLikeIP reports whether a host list item is meant to be an address or
+// netblock, rather than a host name.
+func looksLikeIP(pattern string) bool {
+	if strings.ContainsAny(pattern, "/:[") {
+		return true
+	}
+	for i := 0; i < len(pattern); i++ {
+		if !isDigit(pattern[i]) && pattern[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *NamedLists) isLocalAddress(ip netip.Addr) bool {
+	if !ip.IsValid() {
+		return false
+	}
+	for _, local := range n.LocalAddresses {
+		if local.Unmap() == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDomain returns the form of a domain or host name which is compared:
+// lower-cased, without a trailing dot, and with address-literals in
+// canonical form.
+func matchDomain(domain string) string {
+	if strings.HasPrefix(domain, "[") {
+		return canonicalAddressLiteral(domain)
+	}
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
+
+// literalAddr returns the IP address of an address-literal in canonical
+// form, or the zero Addr.
+func literalAddr(domain string) netip.Addr {
+	if len(domain) < 2 || domain[0] != '[' || domain[len(domain)-1] != ']' {
+		return netip.Addr{}
+	}
+	literal := domain[1 : len(domain)-1]
+	if len(literal) >= 5 && strings.EqualFold(literal[:5], "IPv6:") {
+		literal = literal[5:]
+	}
+	ip, err := netip.ParseAddr(literal)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+// Match matches a domain, which may be an address-literal, against the list.
+func (l *DomainMatchList) Match(domain string) MatchResult {
+	return l.list.match(&matchSubject{domain: matchDomain(domain)})
+}
+
+// Match matches an address against the list.
+func (l *AddressMatchList) Match(a Address) MatchResult {
+	c := a.Canonical()
+	c.Domain = matchDomain(c.Domain)
+	return l.list.match(&matchSubject{
+		domain:    c.Domain,
+		localPart: a.LocalPart,
+		address:   c.String(),
+	})
+}
+
+// MatchString parses an address with the DefaultGrammar and matches it
+// against the list.
+func (l *AddressMatchList) MatchString(address string) (MatchResult, error) {
+	a, err := ParseAddress(address)
+	if err != nil {
+		return MatchResult{}, err
+	}
+	return l.Match(a), nil
+}
+
+// Match matches a host against the list, by its IP address and its name.
+// The name may be empty if it is not known, in which case no item which needs
+// a name will match.  Nothing is looked up in the DNS.
+func (l *HostMatchList) Match(ip netip.Addr, name string) MatchResult {
+	return l.list.match(&matchSubject{ip: ip.Unmap(), host: matchDomain(name)})
+}
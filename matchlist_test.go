@@ -0,0 +1,214 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitMatchList(t *testing.T) {
+	for _, item := range []struct {
+		list string
+		want []string
+	}{
+		{"a : b:c", []string{"a", "b", "c"}},
+		{" a :: b : : c: ", []string{"a : b", "c"}},
+		{"2001::db8::::/32 : 192.0.2.0/24", []string{"2001:db8::/32", "192.0.2.0/24"}},
+		{"<; 2001:db8::/32; 192.0.2.0/24 ;; x", []string{"2001:db8::/32", "192.0.2.0/24 ; x"}},
+		{"", nil},
+	} {
+		if got := splitMatchList(item.list); !reflect.DeepEqual(got, item.want) {
+			t.Errorf("splitMatchList(%q) gave %q, expected %q", item.list, got, item.want)
+		}
+	}
+}
+
+func TestDomainMatchList(t *testing.T) {
+	n := &NamedLists{
+		PrimaryHostname: "mx.Example.ORG",
+		LocalAddresses:  []netip.Addr{netip.MustParseAddr("192.0.2.25"), netip.MustParseAddr("2001:db8::25")},
+	}
+	if err := n.DefineDomainList("local_domains", "@ : example.org : *.example.net : @[]"); err != nil {
+		t.Fatalf("DefineDomainList failed: %v", err)
+	}
+	list, err := n.ParseDomainMatchList("!bad.example.net : ^[a-z]+\\.example\\.com$ : +local_domains")
+	if err != nil {
+		t.Fatalf("ParseDomainMatchList failed: %v", err)
+	}
+	for _, item := range []struct {
+		domain string
+		want   bool
+		why    string
+	}{
+		{"example.org", true, `matched by item 3 "+local_domains", through item 2 "example.org" of +local_domains`},
+		{"EXAMPLE.org", true, `matched by item 3 "+local_domains", through item 2 "example.org" of +local_domains`},
+		{"mx.example.org", true, `matched by item 3 "+local_domains", through item 1 "@" of +local_domains`},
+		{"www.example.net", true, `matched by item 3 "+local_domains", through item 3 "*.example.net" of +local_domains`},
+		{"bad.example.net", false, `not matched by item 1 "!bad.example.net"`},
+		{"example.net", false, `not matched: no item matched`},
+		{"Mail.Example.com", true, `matched by item 2 "^[a-z]+\\.example\\.com$"`},
+		{"a.b.example.com", false, `not matched: no item matched`},
+		{"[192.0.2.25]", true, `matched by item 3 "+local_domains", through item 4 "@[]" of +local_domains`},
+		{"[IPv6:2001:DB8:0::25]", true, `matched by item 3 "+local_domains", through item 4 "@[]" of +local_domains`},
+		{"[ipv6:2001:db8::25]", true, `matched by item 3 "+local_domains", through item 4 "@[]" of +local_domains`},
+		{"[192.0.2.26]", false, `not matched: no item matched`},
+	} {
+		r := list.Match(item.domain)
+		if r.Matched != item.want || r.String() != item.why {
+			t.Errorf("Match(%q) gave %v %q, expected %v %q", item.domain, r.Matched, r, item.want, item.why)
+		}
+	}
+
+	// a list ending with a negated item matches everything else
+	notLocal, err := n.ParseDomainMatchList("! +local_domains")
+	if err != nil {
+		t.Fatalf("ParseDomainMatchList failed: %v", err)
+	}
+	if r := notLocal.Match("example.org"); r.Matched {
+		t.Errorf("!+local_domains matched example.org: %s", r)
+	}
+	r := notLocal.Match("example.com")
+	if want := `matched by reaching the end of the list after a negated item`; !r.Matched || r.String() != want {
+		t.Errorf("!+local_domains gave %q for example.com, expected %q", r, want)
+	}
+}
+
+func TestSingleLabelItems(t *testing.T) {
+	for _, item := range []struct {
+		list   string
+		domain string
+		want   bool
+	}{
+		{"localhost", "localhost", true},
+		{"localhost", "LocalHost.", true},
+		{"localhost", "mail.localhost", false},
+		{"*.org", "example.org", true},
+		{"*.org", "exampleorg", false},
+		{"*.org", "org", false},
+		{"*org", "example.org", true},
+		{"*org", "exampleorg", true},
+		{"*org", "example.net", false},
+	} {
+		list, err := ParseDomainMatchList(item.list)
+		if err != nil {
+			t.Errorf("ParseDomainMatchList(%q) failed: %v", item.list, err)
+			continue
+		}
+		if r := list.Match(item.domain); r.Matched != item.want {
+			t.Errorf("domain list %q gave %v for %q, expected %v", item.list, r.Matched, item.domain, item.want)
+		}
+		hosts, err := ParseHostMatchList(item.list)
+		if err != nil {
+			t.Errorf("ParseHostMatchList(%q) failed: %v", item.list, err)
+			continue
+		}
+		if r := hosts.Match(netip.MustParseAddr("192.0.2.1"), item.domain); r.Matched != item.want {
+			t.Errorf("host list %q gave %v for %q, expected %v", item.list, r.Matched, item.domain, item.want)
+		}
+	}
+}
+
+func TestAddressMatchList(t *testing.T) {
+	n := &NamedLists{PrimaryHostname: "mx.example.org"}
+	if err := n.DefineDomainList("local_domains", "example.org : *.example.org"); err != nil {
+		t.Fatalf("DefineDomainList failed: %v", err)
+	}
+	if err := n.DefineAddressList("blocked", "spammer@example.com : ^.*\\d{6}@"); err != nil {
+		t.Fatalf("DefineAddressList failed: %v", err)
+	}
+	list, err := n.ParseAddressMatchList(`!+blocked : postmaster@* : "john doe"@example.net : *-request@+local_domains : *@@ : example.com`)
+	if err != nil {
+		t.Fatalf("ParseAddressMatchList failed: %v", err)
+	}
+	for _, item := range []struct {
+		address string
+		want    bool
+		why     string
+	}{
+		{"Spammer@EXAMPLE.com", false, `not matched by item 1 "!+blocked", through item 1 "spammer@example.com" of +blocked`},
+		{"user123456@example.org", false, `not matched by item 1 "!+blocked", through item 2 "^.*\\d{6}@" of +blocked`},
+		{"postmaster@anywhere.example", true, `matched by item 2 "postmaster@*"`},
+		{`"John Doe"@example.net`, true, `matched by item 3 "\"john doe\"@example.net"`},
+		{"johndoe@example.net", false, `not matched: no item matched`},
+		{"list-request@lists.example.org", true, `matched by item 4 "*-request@+local_domains", through item 2 "*.example.org" of +local_domains`},
+		{"list-request@example.net", false, `not matched: no item matched`},
+		{"root@mx.example.org", true, `matched by item 5 "*@@"`},
+		{"anyone@Example.COM", true, `matched by item 6 "example.com"`},
+	} {
+		r, err := list.MatchString(item.address)
+		if err != nil {
+			t.Errorf("MatchString(%q) failed: %v", item.address, err)
+			continue
+		}
+		if r.Matched != item.want || r.String() != item.why {
+			t.Errorf("MatchString(%q) gave %v %q, expected %v %q", item.address, r.Matched, r, item.want, item.why)
+		}
+	}
+	if _, err := list.MatchString("not an address"); err == nil {
+		t.Errorf("MatchString(not an address) did not fail")
+	}
+}
+
+func TestHostMatchList(t *testing.T) {
+	n := &NamedLists{LocalAddresses: []netip.Addr{netip.MustParseAddr("198.51.100.1")}}
+	if err := n.DefineHostList("relay_hosts", "<; 192.0.2.0/24; [2001:db8::]/32; *.relay.example.net"); err != nil {
+		t.Fatalf("DefineHostList failed: %v", err)
+	}
+	list, err := n.ParseHostMatchList("!192.0.2.1 : @[] : +relay_hosts : ^mail[0-9]+\\.example\\.com$")
+	if err != nil {
+		t.Fatalf("ParseHostMatchList failed: %v", err)
+	}
+	for _, item := range []struct {
+		ip, name string
+		want     bool
+		why      string
+	}{
+		{"192.0.2.1", "", false, `not matched by item 1 "!192.0.2.1"`},
+		{"192.0.2.2", "", true, `matched by item 3 "+relay_hosts", through item 1 "192.0.2.0/24" of +relay_hosts`},
+		{"::ffff:192.0.2.2", "", true, `matched by item 3 "+relay_hosts", through item 1 "192.0.2.0/24" of +relay_hosts`},
+		{"2001:db8::1", "", true, `matched by item 3 "+relay_hosts", through item 2 "[2001:db8::]/32" of +relay_hosts`},
+		{"198.51.100.1", "", true, `matched by item 2 "@[]"`},
+		{"203.0.113.1", "a.Relay.example.NET.", true, `matched by item 3 "+relay_hosts", through item 3 "*.relay.example.net" of +relay_hosts`},
+		{"203.0.113.1", "mail7.example.com", true, `matched by item 4 "^mail[0-9]+\\.example\\.com$"`},
+		{"203.0.113.1", "", false, `not matched: no item matched`},
+	} {
+		r := list.Match(netip.MustParseAddr(item.ip), item.name)
+		if r.Matched != item.want || r.String() != item.why {
+			t.Errorf("Match(%s, %q) gave %v %q, expected %v %q", item.ip, item.name, r.Matched, r, item.want, item.why)
+		}
+	}
+}
+
+func TestMatchListErrors(t *testing.T) {
+	n := &NamedLists{}
+	if err := n.DefineDomainList("local_domains", "example.org"); err != nil {
+		t.Fatalf("DefineDomainList failed: %v", err)
+	}
+	for _, item := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unknown named list", n.DefineDomainList("x", "+nowhere"), `no domain list named "nowhere"`},
+		{"wrong kind of named list", n.DefineHostList("x", "+local_domains"), `no host list named "local_domains"`},
+		{"redefined", n.DefineDomainList("local_domains", "example.com"), "already defined"},
+		{"bad name", n.DefineDomainList("a b", "example.com"), "invalid domain list name"},
+		{"bad domain", n.DefineDomainList("y", "example..org"), `domain list +y item 1 "example..org"`},
+		{"bad suffix", wrapParse(ParseDomainMatchList("*.")), `domain list item 1 "*."`},
+		{"bad single label", wrapParse(ParseHostMatchList("-localhost")), `host list item 1 "-localhost"`},
+		{"bad regexp", wrapParse(ParseDomainMatchList("^(")), "missing closing )"},
+		{"lookup", wrapParse(ParseDomainMatchList("example.org : lsearch;/etc/domains")), "lookups and expansions are not supported"},
+		{"bad local part", wrapParse(ParseAddressMatchList("john..doe@example.org")), `address list item 1`},
+		{"empty local part", wrapParse(ParseAddressMatchList("@example.org")), "empty local part"},
+		{"bad netblock", wrapParse(ParseHostMatchList("<; 192.0.2.0/33")), `host list item 1 "192.0.2.0/33"`},
+	} {
+		if item.err == nil || !strings.Contains(item.err.Error(), item.want) {
+			t.Errorf("%s: gave error %v, expected it to contain %q", item.name, item.err, item.want)
+		}
+	}
+}
+
+func wrapParse(_ interface{}, err error) error { return err }
@@ -0,0 +1,317 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A NetblockSet is a source ACL, as used by Exim's host lists and Postfix's
+// `mynetworks`: an ordered list of netblocks, each of which may be negated,
+// where the first entry to match an address decides the answer.  So
+// `!192.0.2.1, 192.0.2.0/24` contains all of 192.0.2.0/24 except one address.
+//
+// The list is compiled into sorted, merged ranges, so Contains takes time
+// logarithmic in the size of the list.  As with Exim and Postfix, IPv4
+// netblocks only match IPv4 addresses and IPv6 netblocks only match IPv6
+// addresses, so `::/0` does not include `192.0.2.1`; an IPv4-mapped address
+// such as `::ffff:192.0.2.1` is treated as the IPv4 address.
+type NetblockSet struct {
+	v4, v6 []ipRange
+}
+
+// NetblockEntry is one item in the ordered list for a NetblockSet.
+type NetblockEntry struct {
+	Prefix  netip.Prefix
+	Negated bool
+}
+
+// NewNetblockSet compiles the entries, which are not retained.  An entry with
+// an invalid Prefix is ignored.
+func NewNetblockSet(entries []NetblockEntry) *NetblockSet {
+	return &NetblockSet{
+		v4: compileNetblocks(entries, true),
+		v6: compileNetblocks(entries, false),
+	}
+}
+
+// compileNetblocks turns the entries for one address family into ranges.
+func compileNetblocks(entries []NetblockEntry, is4 bool) []ipRange {
+	type event struct {
+		at    uint128
+		index int
+		open  bool
+	}
+	events := make([]event, 0, 2*len(entries))
+	for i, entry := range entries {
+		if !entry.Prefix.IsValid() || entry.Prefix.Addr().Is4() != is4 {
+			continue
+		}
+		r := prefixRange(entry.Prefix)
+		events = append(events, event{at: r.lo, index: i, open: true})
+		if after, ok := r.hi.next(); ok {
+			events = append(events, event{at: after, index: i})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].at.less(events[j].at) })
+
+	// Sweep through the boundaries, keeping the indices of the entries
+	// covering the current position in a heap: the lowest index is the first
+	// entry to match, so decides the answer until the next boundary.
+	var ranges []ipRange
+	active := &intHeap{}
+	closed := make(map[int]bool)
+	for k := 0; k < len(events); {
+		at := events[k].at
+		for ; k < len(events) && events[k].at == at; k++ {
+			if events[k].open {
+				heap.Push(active, events[k].index)
+			} else {
+				closed[events[k].index] = true
+			}
+		}
+		for active.Len() > 0 && closed[(*active)[0]] {
+			heap.Pop(active)
+		}
+		if active.Len() == 0 || entries[(*active)[0]].Negated {
+			continue
+		}
+		hi := uint128{^uint64(0), ^uint64(0)}
+		if k < len(events) {
+			hi = events[k].at.prev()
+		}
+		// merge with the previous range if adjacent
+		if n := len(ranges); n > 0 {
+			if after, ok := ranges[n-1].hi.next(); ok && after == at {
+				ranges[n-1].hi = hi
+				continue
+			}
+		}
+		ranges = append(ranges, ipRange{lo: at, hi: hi})
+	}
+	return ranges
+}
+
+// Contains reports whether the address is in the set.
+func (s *NetblockSet) Contains(ip netip.Addr) bool {
+	if !ip.IsValid() {
+		return false
+	}
+	ip = ip.Unmap()
+	ranges := s.v6
+	if ip.Is4() {
+		ranges = s.v4
+	}
+	a := addrToUint128(ip)
+	i := sort.Search(len(ranges), func(i int) bool { return !ranges[i].hi.less(a) })
+	return i < len(ranges) && !a.less(ranges[i].lo)
+}
+
+// Prefixes returns the smallest list of prefixes covering exactly the
+// addresses in the set, IPv4 first, in order.
+func (s *NetblockSet) Prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, r := range s.v4 {
+		prefixes = appendRangePrefixes(prefixes, r, true)
+	}
+	for _, r := range s.v6 {
+		prefixes = appendRangePrefixes(prefixes, r, false)
+	}
+	return prefixes
+}
+
+// appendRangePrefixes splits a range into the largest aligned prefixes.
+func appendRangePrefixes(prefixes []netip.Prefix, r ipRange, is4 bool) []netip.Prefix {
+	for lo := r.lo; ; {
+		bitsLen := 0
+		for ; bitsLen < 128; bitsLen++ {
+			host := hostMask(bitsLen)
+			if lo.and(host).isZero() && !r.hi.less(lo.or(host)) {
+				break
+			}
+		}
+		prefixes = append(prefixes, uint128ToPrefix(lo, bitsLen, is4))
+		end := lo.or(hostMask(bitsLen))
+		if end == r.hi {
+			return prefixes
+		}
+		lo, _ = end.next()
+	}
+}
+
+// ParseNetblockEntry parses one item of a host list: a netblock matched by
+// `IPNetblock` or an address matched by `IPv4Address` or `IPv6Address`,
+// optionally preceded by `!` to negate it.  As Postfix permits, an IPv6
+// address may be in square brackets, as `[2001:db8::]/32`.  Host bits set
+// beyond the prefix length are cleared, as Exim does.
+func ParseNetblockEntry(item string) (NetblockEntry, error) {
+	var entry NetblockEntry
+	if strings.HasPrefix(item, "!") {
+		entry.Negated = true
+		item = item[1:]
+	}
+	if strings.HasPrefix(item, "[") {
+		if end := strings.IndexByte(item, ']'); end > 0 {
+			item = item[1:end] + item[end+1:]
+		}
+	}
+	if strings.IndexByte(item, '/') < 0 {
+		var addr netip.Addr
+		var err error
+		if strings.IndexByte(item, ':') >= 0 {
+			addr, err = ParseIPv6Address(item)
+		} else {
+			addr, err = ParseIPv4Address(item)
+		}
+		if err != nil {
+			return NetblockEntry{}, err
+		}
+		entry.Prefix = netip.PrefixFrom(addr, addr.BitLen())
+		return entry, nil
+	}
+	prefix, _, err := ParseIPNetblock(item)
+	if err != nil {
+		return NetblockEntry{}, err
+	}
+	entry.Prefix = prefix.Masked()
+	return entry, nil
+}
+
+// LoadNetblockSet reads a host list in the style of an Exim or Postfix file:
+// items separated by white-space or commas, each as for ParseNetblockEntry,
+// with `#` starting a comment to the end of the line.  Exim's doubling of
+// colons in IPv6 addresses is for lists written inline in its configuration,
+// not in files, so is not handled here.
+func LoadNetblockSet(r io.Reader) (*NetblockSet, error) {
+	var entries []NetblockEntry
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		items := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})
+		for _, item := range items {
+			entry, err := ParseNetblockEntry(item)
+			if err != nil {
+				return nil, fmt.Errorf("emailsupport: netblock list line %d: %w", lineNum, err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewNetblockSet(entries), nil
+}
+
+// LoadNetblockSetFile reads a host list from a file; see LoadNetblockSet.
+func LoadNetblockSetFile(filename string) (*NetblockSet, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	s, err := LoadNetblockSet(fh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return s, nil
+}
+
+// uint128 is an IPv6 address (or IPv4-mapped address) as a number.
+type uint128 struct {
+	hi, lo uint64
+}
+
+// addrToUint128 converts an address; an IPv4 address becomes IPv4-mapped
+func addrToUint128(ip netip.Addr) uint128 {
+	b := ip.As16()
+	return uint128{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+func (u uint128) less(v uint128) bool {
+	return u.hi < v.hi || u.hi == v.hi && u.lo < v.lo
+}
+
+func (u uint128) and(v uint128) uint128 { return uint128{u.hi & v.hi, u.lo & v.lo} }
+func (u uint128) or(v uint128) uint128  { return uint128{u.hi | v.hi, u.lo | v.lo} }
+func (u uint128) isZero() bool          { return u.hi == 0 && u.lo == 0 }
+
+// next returns u+1, and false if that overflowed
+func (u uint128) next() (uint128, bool) {
+	lo, carry := bits.Add64(u.lo, 1, 0)
+	hi, overflow := bits.Add64(u.hi, 0, carry)
+	return uint128{hi, lo}, overflow == 0
+}
+
+// prev returns u-1; it is only used where u is not zero
+func (u uint128) prev() uint128 {
+	lo, borrow := bits.Sub64(u.lo, 1, 0)
+	return uint128{u.hi - borrow, lo}
+}
+
+// hostMask has the low 128-bitsLen bits set
+func hostMask(bitsLen int) uint128 {
+	switch {
+	case bitsLen <= 0:
+		return uint128{^uint64(0), ^uint64(0)}
+	case bitsLen < 64:
+		return uint128{^uint64(0) >> bitsLen, ^uint64(0)}
+	case bitsLen < 128:
+		return uint128{0, ^uint64(0) >> (bitsLen - 64)}
+	}
+	return uint128{}
+}
+
+// ipRange is an inclusive range of addresses
+type ipRange struct {
+	lo, hi uint128
+}
+
+func prefixRange(p netip.Prefix) ipRange {
+	bitsLen := p.Bits()
+	if p.Addr().Is4() {
+		bitsLen += 96
+	}
+	host := hostMask(bitsLen)
+	lo := addrToUint128(p.Addr())
+	lo = uint128{lo.hi &^ host.hi, lo.lo &^ host.lo}
+	return ipRange{lo: lo, hi: lo.or(host)}
+}
+
+func uint128ToPrefix(u uint128, bitsLen int, is4 bool) netip.Prefix {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.hi)
+	binary.BigEndian.PutUint64(b[8:], u.lo)
+	addr := netip.AddrFrom16(b)
+	if is4 {
+		return netip.PrefixFrom(addr.Unmap(), bitsLen-96)
+	}
+	return netip.PrefixFrom(addr, bitsLen)
+}
+
+// intHeap is a min-heap of entry indices, for container/heap
+type intHeap []int
+
+func (h intHeap) Len() int            { return len(h) }
+func (h intHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h intHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *intHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *intHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
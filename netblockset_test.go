@@ -0,0 +1,179 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"math/rand"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNetblockSet(t *testing.T) {
+	set, err := LoadNetblockSet(strings.NewReader(`
+# local networks
+!192.0.2.1, 192.0.2.0/24
+192.0.2.128/25       # already covered, so merged
+198.51.100.0/25 198.51.100.128/25
+!2001:db8::/48
+[2001:db8::]/32
+203.0.113.7/24       # host bits are masked
+!::/0                # IPv6 only, so does not hide the next line
+10.0.0.0/8
+`))
+	if err != nil {
+		t.Fatalf("LoadNetblockSet failed: %v", err)
+	}
+	for _, item := range []struct {
+		addr string
+		want bool
+	}{
+		{"192.0.2.0", true},
+		{"192.0.2.1", false},
+		{"192.0.2.2", true},
+		{"192.0.2.255", true},
+		{"192.0.3.0", false},
+		{"198.51.100.0", true},
+		{"198.51.100.255", true},
+		{"203.0.113.0", true},
+		{"203.0.113.255", true},
+		{"10.1.2.3", true},
+		{"::ffff:192.0.2.2", true},
+		{"::ffff:192.0.2.1", false},
+		{"2001:db8::1", false},
+		{"2001:db8:0:ffff::1", false},
+		{"2001:db8:1::1", true},
+		{"2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", true},
+		{"2001:db9::", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+	} {
+		if got := set.Contains(netip.MustParseAddr(item.addr)); got != item.want {
+			t.Errorf("Contains(%s) gave %v, expected %v", item.addr, got, item.want)
+		}
+	}
+	if set.Contains(netip.Addr{}) {
+		t.Errorf("Contains(invalid) gave true")
+	}
+
+	var got []string
+	for _, p := range set.Prefixes() {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"10.0.0.0/8",
+		"192.0.2.0/32", "192.0.2.2/31", "192.0.2.4/30", "192.0.2.8/29", "192.0.2.16/28",
+		"192.0.2.32/27", "192.0.2.64/26", "192.0.2.128/25",
+		"198.51.100.0/24",
+		"203.0.113.0/24",
+		"2001:db8:1::/48", "2001:db8:2::/47", "2001:db8:4::/46", "2001:db8:8::/45",
+		"2001:db8:10::/44", "2001:db8:20::/43", "2001:db8:40::/42", "2001:db8:80::/41",
+		"2001:db8:100::/40", "2001:db8:200::/39", "2001:db8:400::/38", "2001:db8:800::/37",
+		"2001:db8:1000::/36", "2001:db8:2000::/35", "2001:db8:4000::/34", "2001:db8:8000::/33",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Prefixes gave:\n%v\nexpected:\n%v", got, want)
+	}
+}
+
+func TestNetblockSetEverything(t *testing.T) {
+	set := NewNetblockSet([]NetblockEntry{
+		{Prefix: netip.MustParsePrefix("::/0")},
+		{Prefix: netip.MustParsePrefix("0.0.0.0/0")},
+	})
+	for _, addr := range []string{"::", "192.0.2.1", "::ffff:192.0.2.1", "255.255.255.255", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"} {
+		if !set.Contains(netip.MustParseAddr(addr)) {
+			t.Errorf("Contains(%s) gave false", addr)
+		}
+	}
+	want := []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")}
+	if got := set.Prefixes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Prefixes gave %v", got)
+	}
+	v6only := NewNetblockSet([]NetblockEntry{{Prefix: netip.MustParsePrefix("::/0")}})
+	if v6only.Contains(netip.MustParseAddr("192.0.2.1")) {
+		t.Errorf("::/0 contains an IPv4 address")
+	}
+	if empty := NewNetblockSet(nil); empty.Contains(netip.MustParseAddr("192.0.2.1")) || len(empty.Prefixes()) != 0 {
+		t.Errorf("empty set is not empty")
+	}
+}
+
+func TestParseNetblockEntryErrors(t *testing.T) {
+	for _, bad := range []string{
+		"", "!", "192.0.2.0/33", "192.0.2.01", "example.org", "*", "[192.0.2.1", "2001:db8::/129", "!!192.0.2.1",
+	} {
+		if entry, err := ParseNetblockEntry(bad); err == nil {
+			t.Errorf("ParseNetblockEntry(%q) accepted, giving %v", bad, entry)
+		}
+	}
+	if _, err := LoadNetblockSet(strings.NewReader("192.0.2.0/24\nexample.org\n")); err == nil ||
+		!strings.Contains(err.Error(), "line 2") {
+		t.Errorf("LoadNetblockSet error did not give the line: %v", err)
+	}
+}
+
+func TestLoadNetblockSetFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(filename, []byte("192.0.2.0/24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := LoadNetblockSetFile(filename)
+	if err != nil {
+		t.Fatalf("LoadNetblockSetFile failed: %v", err)
+	}
+	if !set.Contains(netip.MustParseAddr("192.0.2.99")) {
+		t.Errorf("loaded set does not contain 192.0.2.99")
+	}
+	if _, err := LoadNetblockSetFile(filename + ".missing"); err == nil {
+		t.Errorf("LoadNetblockSetFile of missing file succeeded")
+	}
+}
+
+// TestNetblockSetAgainstLinear checks the compiled set against a linear
+// first-match walk of the entries, with random overlapping netblocks.
+func TestNetblockSetAgainstLinear(t *testing.T) {
+	rng := rand.New(rand.NewSource(1918))
+	for round := 0; round < 200; round++ {
+		entries := make([]NetblockEntry, rng.Intn(12))
+		for i := range entries {
+			addr := netip.AddrFrom4([4]byte{192, 0, byte(rng.Intn(4)), byte(rng.Intn(256))})
+			if rng.Intn(3) == 0 {
+				addr = netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 15: byte(rng.Intn(256))})
+			}
+			entries[i] = NetblockEntry{
+				Prefix:  netip.PrefixFrom(addr, addr.BitLen()-rng.Intn(11)).Masked(),
+				Negated: rng.Intn(2) == 0,
+			}
+		}
+		set := NewNetblockSet(entries)
+		for probe := 0; probe < 200; probe++ {
+			addr := netip.AddrFrom4([4]byte{192, 0, byte(rng.Intn(5)), byte(rng.Intn(256))})
+			if rng.Intn(3) == 0 {
+				addr = netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 14: byte(rng.Intn(8)), 15: byte(rng.Intn(256))})
+			}
+			want := false
+			for _, entry := range entries {
+				if entry.Prefix.Contains(addr) {
+					want = !entry.Negated
+					break
+				}
+			}
+			if got := set.Contains(addr); got != want {
+				t.Fatalf("entries %v: Contains(%s) gave %v, expected %v", entries, addr, got, want)
+			}
+		}
+		// the prefixes, taken as a list without negation, give the same set
+		var listed []NetblockEntry
+		for _, p := range set.Prefixes() {
+			listed = append(listed, NetblockEntry{Prefix: p})
+		}
+		if again := NewNetblockSet(listed); !reflect.DeepEqual(again, set) {
+			t.Fatalf("entries %v: Prefixes %v do not rebuild the same set", entries, set.Prefixes())
+		}
+	}
+}
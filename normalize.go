@@ -0,0 +1,204 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Canonical forms follow the RFCs, but mail providers are free to treat more
+// local parts as the same mailbox: Gmail ignores dots and anything after a
+// `+`, and delivers mail for googlemail.com to the same place.  Knowing this
+// is useful for spotting one person signing up many times; it is not safe for
+// deciding where to deliver mail, and the knowledge goes stale, so the rules
+// are data which can be loaded from a file rather than being built in.
+
+// NormalizationRule describes how one provider treats local parts.  The first
+// of the Domains is the one used in normalised addresses; any others are
+// aliases of it.
+type NormalizationRule struct {
+	Domains    []string
+	IgnoreDots bool
+	FoldCase   bool
+	Delimiters RecipientDelimiters
+}
+
+// DefaultNormalizationRules returns the rules built in to DefaultNormalizer.
+// They are best-effort, covering only providers with well-known behaviour.
+// The slice is new on each call, so changing it does not change
+// DefaultNormalizer; pass it to NewNormalizer for a Normalizer with the
+// changes.
+func DefaultNormalizationRules() []NormalizationRule {
+	return []NormalizationRule{
+		{Domains: []string{"gmail.com", "googlemail.com"}, IgnoreDots: true, FoldCase: true, Delimiters: RecipientDelimiters{"+"}},
+		{Domains: []string{"outlook.com"}, FoldCase: true, Delimiters: RecipientDelimiters{"+"}},
+		{Domains: []string{"hotmail.com"}, FoldCase: true, Delimiters: RecipientDelimiters{"+"}},
+		{Domains: []string{"fastmail.com"}, FoldCase: true, Delimiters: RecipientDelimiters{"+"}},
+	}
+}
+
+// Normalizer applies NormalizationRules, looked up by domain.  It is safe for
+// concurrent use.
+type Normalizer struct {
+	rules map[string]*NormalizationRule
+}
+
+// DefaultNormalizer uses the DefaultNormalizationRules.
+var DefaultNormalizer = mustNormalizer(DefaultNormalizationRules())
+
+// NewNormalizer returns a Normalizer for the rules, which are copied.  It is
+// an error for a domain to be invalid or to appear in more than one rule.
+func NewNormalizer(rules []NormalizationRule) (*Normalizer, error) {
+	n := &Normalizer{rules: make(map[string]*NormalizationRule)}
+	for i := range rules {
+		if len(rules[i].Domains) == 0 {
+			return nil, fmt.Errorf("emailsupport: normalization rule %d has no domains", i+1)
+		}
+		rule := rules[i]
+		rule.Domains = make([]string, len(rules[i].Domains))
+		rule.Delimiters = append(RecipientDelimiters(nil), rules[i].Delimiters...)
+		for j, domain := range rules[i].Domains {
+			if err := ValidateEmailDomain(domain); err != nil {
+				return nil, err
+			}
+			domain = strings.ToLower(domain)
+			if _, ok := n.rules[domain]; ok {
+				return nil, fmt.Errorf("emailsupport: domain %q in more than one normalization rule", domain)
+			}
+			rule.Domains[j] = domain
+			n.rules[domain] = &rule
+		}
+	}
+	return n, nil
+}
+
+func mustNormalizer(rules []NormalizationRule) *Normalizer {
+	n, err := NewNormalizer(rules)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Rule returns a copy of the rule for a domain, and false if there is none.
+func (n *Normalizer) Rule(domain string) (NormalizationRule, bool) {
+	rule := n.rules[strings.ToLower(domain)]
+	if rule == nil {
+		return NormalizationRule{}, false
+	}
+	r := *rule
+	r.Domains = append([]string(nil), rule.Domains...)
+	r.Delimiters = append(RecipientDelimiters(nil), rule.Delimiters...)
+	return r, true
+}
+
+// Normalize returns the canonical form of the address, with the rule for its
+// domain (if any) applied: the sub-address detail is removed, then dots are
+// removed and case folded as the rule says, and the domain replaced by the
+// first of the rule's domains.
+func (n *Normalizer) Normalize(a Address) Address {
+	a = a.Canonical()
+	if a.AddressLiteral {
+		return a
+	}
+	rule := n.rules[a.Domain]
+	if rule == nil {
+		return a
+	}
+	local := rule.Delimiters.Split(a.LocalPart).Base
+	if rule.IgnoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if rule.FoldCase {
+		local = strings.ToLower(local)
+	}
+	a.Domain = rule.Domains[0]
+	return a.WithSubAddress(SubAddress{Base: local})
+}
+
+// NormalizeString parses an address and returns the normalised form, as a
+// string.
+func (n *Normalizer) NormalizeString(address string) (string, error) {
+	a, err := ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return n.Normalize(a).String(), nil
+}
+
+// Equivalent parses two addresses and reports whether they normalise to the
+// same mailbox.
+func (n *Normalizer) Equivalent(x, y string) (bool, error) {
+	a, err := n.NormalizeString(x)
+	if err != nil {
+		return false, err
+	}
+	b, err := n.NormalizeString(y)
+	if err != nil {
+		return false, err
+	}
+	return a == b, nil
+}
+
+// LoadNormalizationRules reads rules, one per line, in the form:
+//
+//	gmail.com googlemail.com: ignore-dots fold-case delimiters=+
+//
+// being the domains, a colon, then the options.  The options are
+// `ignore-dots`, `fold-case` and `delimiters=` followed by the delimiter
+// characters, as for Postfix's `recipient_delimiter`.  Blank lines and lines
+// starting with `#` are ignored.
+func LoadNormalizationRules(r io.Reader) ([]NormalizationRule, error) {
+	var rules []NormalizationRule
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		domains, options, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("emailsupport: normalization rules line %d: missing ':'", lineNum)
+		}
+		rule := NormalizationRule{Domains: strings.Fields(domains)}
+		if len(rule.Domains) == 0 {
+			return nil, fmt.Errorf("emailsupport: normalization rules line %d: no domains", lineNum)
+		}
+		for _, option := range strings.Fields(options) {
+			switch name, value, _ := strings.Cut(option, "="); name {
+			case "ignore-dots":
+				rule.IgnoreDots = true
+			case "fold-case":
+				rule.FoldCase = true
+			case "delimiters":
+				rule.Delimiters = ParseRecipientDelimiters(value)
+			default:
+				return nil, fmt.Errorf("emailsupport: normalization rules line %d: unknown option %q", lineNum, option)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadNormalizerFile reads rules from a file with LoadNormalizationRules and
+// returns a Normalizer for them.
+func LoadNormalizerFile(filename string) (*Normalizer, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	rules, err := LoadNormalizationRules(fh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return NewNormalizer(rules)
+}
@@ -0,0 +1,136 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultNormalizer(t *testing.T) {
+	for _, item := range []struct {
+		in, out string
+	}{
+		{`john@gmail.com`, `john@gmail.com`},
+		{`j.o.h.n+x@gmail.com`, `john@gmail.com`},
+		{`J.O.H.N+x@GoogleMail.COM`, `john@gmail.com`},
+		{`"j.ohn+x y"@gmail.com`, `john@gmail.com`},
+		{`john+x@outlook.com`, `john@outlook.com`},
+		{`j.ohn+x@outlook.com`, `j.ohn@outlook.com`},
+		{`John+x@example.org`, `John+x@example.org`},
+		{`"john"@Example.ORG`, `john@example.org`},
+		{`john@[IPv6:2001:DB8::42]`, `john@[IPv6:2001:db8::42]`},
+	} {
+		got, err := DefaultNormalizer.NormalizeString(item.in)
+		if err != nil {
+			t.Errorf("NormalizeString(%q) failed: %v", item.in, err)
+			continue
+		}
+		if got != item.out {
+			t.Errorf("NormalizeString(%q) gave %q, expected %q", item.in, got, item.out)
+		}
+	}
+
+	same, err := DefaultNormalizer.Equivalent(`j.o.h.n+x@gmail.com`, `john@googlemail.com`)
+	if err != nil || !same {
+		t.Errorf("Equivalent gmail addresses gave %v, %v", same, err)
+	}
+	same, err = DefaultNormalizer.Equivalent(`john@gmail.com`, `jane@gmail.com`)
+	if err != nil || same {
+		t.Errorf("different gmail addresses gave %v, %v", same, err)
+	}
+	if _, err = DefaultNormalizer.Equivalent(`john@`, `john@gmail.com`); err == nil {
+		t.Errorf("Equivalent accepted a bad address")
+	}
+
+	rules := DefaultNormalizationRules()
+	rules[0].IgnoreDots = false
+	rules[0].Domains[0] = "changed.example"
+	if again := DefaultNormalizationRules(); !again[0].IgnoreDots || again[0].Domains[0] != "gmail.com" {
+		t.Errorf("changing the DefaultNormalizationRules changed the next call: %v", again[0])
+	}
+}
+
+func TestNewNormalizerErrors(t *testing.T) {
+	for _, rules := range [][]NormalizationRule{
+		{{}},
+		{{Domains: []string{"bad..domain"}}},
+		{{Domains: []string{"example.org"}}, {Domains: []string{"Example.ORG"}}},
+	} {
+		if _, err := NewNormalizer(rules); err == nil {
+			t.Errorf("NewNormalizer(%v) succeeded", rules)
+		}
+	}
+}
+
+func TestLoadNormalizationRules(t *testing.T) {
+	rules, err := LoadNormalizationRules(strings.NewReader(`
+# comment
+example.org example.net: ignore-dots delimiters=+-
+example.com:fold-case
+`))
+	if err != nil {
+		t.Fatalf("LoadNormalizationRules failed: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("LoadNormalizationRules gave %d rules, expected 2", len(rules))
+	}
+	n, err := NewNormalizer(rules)
+	if err != nil {
+		t.Fatalf("NewNormalizer failed: %v", err)
+	}
+	for _, item := range []struct {
+		in, out string
+	}{
+		{`J.ohn-x@example.net`, `John@example.org`},
+		{`John+x@example.com`, `john+x@example.com`},
+		{`John+x@example.edu`, `John+x@example.edu`},
+	} {
+		got, err := n.NormalizeString(item.in)
+		if err != nil || got != item.out {
+			t.Errorf("NormalizeString(%q) gave %q, %v; expected %q", item.in, got, err, item.out)
+		}
+	}
+	r, ok := n.Rule("EXAMPLE.net")
+	if !ok || r.Domains[0] != "example.org" {
+		t.Errorf("Rule(EXAMPLE.net) gave %v, %v", r, ok)
+	}
+	// the rule is a copy, so changing it does not change the Normalizer
+	r.Domains[0] = "changed.example"
+	r.FoldCase = false
+	if got, _ := n.NormalizeString(`J.ohn-x@example.net`); got != `John@example.org` {
+		t.Errorf("changing the rule from Rule changed the Normalizer: gave %q", got)
+	}
+	if _, ok := n.Rule("example.edu"); ok {
+		t.Errorf("Rule(example.edu) found a rule")
+	}
+
+	for _, bad := range []string{
+		"example.org ignore-dots\n",
+		": ignore-dots\n",
+		"example.org: ignore-case\n",
+	} {
+		if _, err := LoadNormalizationRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadNormalizationRules(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestLoadNormalizerFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rules")
+	if err := os.WriteFile(filename, []byte("gmail.com googlemail.com: ignore-dots fold-case delimiters=+\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := LoadNormalizerFile(filename)
+	if err != nil {
+		t.Fatalf("LoadNormalizerFile failed: %v", err)
+	}
+	if got, err := n.NormalizeString(`j.o.h.n+x@googlemail.com`); err != nil || got != `john@gmail.com` {
+		t.Errorf("NormalizeString gave %q, %v", got, err)
+	}
+	if _, err := LoadNormalizerFile(filename + ".missing"); err == nil {
+		t.Errorf("LoadNormalizerFile of missing file succeeded")
+	}
+}
@@ -0,0 +1,290 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Address is an RFC5321 email address, split into its component parts.
+//
+// The LocalPart has any quoting removed, so for `"john doe"@example.org` the
+// LocalPart is `john doe` and QuotedLocalPart is true.  The Domain is exactly
+// as it appeared in the input, so an address-literal retains its square
+// brackets (and any `IPv6:` tag) and AddressLiteral is true.
+type Address struct {
+	LocalPart       string
+	Domain          string
+	QuotedLocalPart bool
+	AddressLiteral  bool
+}
+
+// String returns the address in the form used within SMTP, re-quoting the
+// local part if it was quoted in the original.
+func (a Address) String() string {
+	return a.localPartString() + "@" + a.Domain
+}
+
+func (a Address) localPartString() string {
+	if !a.QuotedLocalPart {
+		return a.LocalPart
+	}
+	var b strings.Builder
+	b.Grow(len(a.LocalPart) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(a.LocalPart); i++ {
+		switch c := a.LocalPart[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseError describes why some text could not be parsed.  The Production is
+// the name of the grammar rule which could not be satisfied, using the names
+// from the RFC, and Offset is the byte offset within Input at which parsing
+// failed.
+type ParseError struct {
+	Input      string
+	Production string
+	Offset     int
+	Reason     string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("emailsupport: bad %s at offset %d in %q: %s",
+		e.Production, e.Offset, e.Input, e.Reason)
+}
+
+// ParseAddress parses an RFC5321 email address, accepting exactly those
+// addresses which are matched by `EmailAddress`.  On failure, the error
+// returned is a *ParseError.
+func ParseAddress(s string) (Address, error) {
+	return defaultGrammar.ParseAddress(s)
+}
+
+func (p *addrParser) address() (Address, error) {
+	var (
+		a   Address
+		err error
+	)
+	if a.LocalPart, a.QuotedLocalPart, err = p.localPart(); err != nil {
+		return Address{}, err
+	}
+	if !p.consume('@') {
+		return Address{}, p.fail("Mailbox", p.pos, "expected '@' after Local-part, found "+p.describe(p.pos))
+	}
+	if a.Domain, a.AddressLiteral, err = p.domain(); err != nil {
+		return Address{}, err
+	}
+	if p.pos != len(p.in) {
+		return Address{}, p.fail("Mailbox", p.pos, "unexpected "+p.describe(p.pos)+" after Domain")
+	}
+	return a, nil
+}
+
+// addrParser is a simple recursive-descent parser over the productions used
+// in `TxtEmailLHS` and `TxtEmailDomain`.  The pos field always indicates the
+// next byte to be examined.  The grammar must be set.  A
+// General-address-literal is only accepted if generalLiterals is set.
+type addrParser struct {
+	in              string
+	pos             int
+	grammar         *Grammar
+	generalLiterals bool
+}
+
+func (p *addrParser) fail(production string, offset int, reason string) *ParseError {
+	return &ParseError{Input: p.in, Production: production, Offset: offset, Reason: reason}
+}
+
+// describe gives a human-readable form of the byte at offset, for error messages
+func (p *addrParser) describe(offset int) string {
+	if offset >= len(p.in) {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", p.in[offset])
+}
+
+func (p *addrParser) peek() (byte, bool) {
+	if p.pos >= len(p.in) {
+		return 0, false
+	}
+	return p.in[p.pos], true
+}
+
+func (p *addrParser) consume(c byte) bool {
+	if next, ok := p.peek(); ok && next == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// localPart returns the unquoted local part, and whether it was quoted
+func (p *addrParser) localPart() (string, bool, error) {
+	c, ok := p.peek()
+	if !ok {
+		return "", false, p.fail("Local-part", p.pos, "empty")
+	}
+	if c == '"' {
+		s, err := p.quotedString()
+		return s, true, err
+	}
+	s, err := p.dotString()
+	return s, false, err
+}
+
+func (p *addrParser) dotString() (string, error) {
+	start := p.pos
+	for {
+		atomStart := p.pos
+		for c, ok := p.peek(); ok && isAText(c); c, ok = p.peek() {
+			p.pos++
+		}
+		if p.pos == atomStart {
+			return "", p.fail("Atom", p.pos, "expected atext, found "+p.describe(p.pos))
+		}
+		if !p.consume('.') {
+			return p.in[start:p.pos], nil
+		}
+	}
+}
+
+func (p *addrParser) quotedString() (string, error) {
+	start := p.pos
+	p.pos++ // opening DQUOTE
+	var b strings.Builder
+	for {
+		c, ok := p.peek()
+		switch {
+		case !ok:
+			return "", p.fail("Quoted-string", p.pos,
+				fmt.Sprintf("missing closing DQUOTE for string opened at offset %d", start))
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.in) || !p.grammar.isQPairFollow(p.in[p.pos+1]) {
+				return "", p.fail("quoted-pair", p.pos+1, "can not quote "+p.describe(p.pos+1))
+			}
+			b.WriteByte(p.in[p.pos+1])
+			p.pos += 2
+		case p.grammar.isQContent(c):
+			b.WriteByte(c)
+			p.pos++
+		default:
+			return "", p.fail("Quoted-string", p.pos, "unexpected "+p.describe(p.pos))
+		}
+	}
+}
+
+// domain returns the domain as written, and whether it is an address-literal
+func (p *addrParser) domain() (string, bool, error) {
+	if c, ok := p.peek(); ok && c == '[' {
+		s, err := p.addressLiteral()
+		return s, true, err
+	}
+	start := p.pos
+	labels := 0
+	for {
+		if err := p.subDomain(); err != nil {
+			return "", false, err
+		}
+		labels++
+		if !p.consume('.') {
+			break
+		}
+	}
+	if labels < 2 {
+		return "", false, p.fail("Domain", start, "need at least two labels in a domain")
+	}
+	return p.in[start:p.pos], false, nil
+}
+
+func (p *addrParser) subDomain() error {
+	if c, ok := p.peek(); !ok || !isLetDig(c) {
+		return p.fail("sub-domain", p.pos, "expected letter or digit, found "+p.describe(p.pos))
+	}
+	p.pos++
+	for c, ok := p.peek(); ok && (isLetDig(c) || c == '-'); c, ok = p.peek() {
+		p.pos++
+	}
+	if p.in[p.pos-1] == '-' {
+		return p.fail("sub-domain", p.pos-1, "may not end with a hyphen")
+	}
+	return nil
+}
+
+func (p *addrParser) addressLiteral() (string, error) {
+	start := p.pos
+	closing := strings.IndexByte(p.in[start:], ']')
+	if closing < 0 {
+		return "", p.fail("address-literal", len(p.in), "missing closing ']'")
+	}
+	closing += start
+	content := p.in[start+1 : closing]
+	p.pos = start + 1
+	switch {
+	case len(content) >= 5 && strings.EqualFold(content[:5], "IPv6:"):
+		p.pos += 5
+		if err := p.ipv6Address(); err != nil {
+			return "", err
+		}
+	case strings.IndexByte(content, ':') > 0:
+		if !p.generalLiterals {
+			return "", p.fail("General-address-literal", start+1, "not supported")
+		}
+		if err := p.generalAddressLiteral(); err != nil {
+			return "", err
+		}
+	default:
+		if err := p.ipv4Address(); err != nil {
+			return "", err
+		}
+	}
+	if p.pos != closing {
+		return "", p.fail("address-literal", p.pos, "unexpected "+p.describe(p.pos))
+	}
+	p.pos = closing + 1
+	return p.in[start:p.pos], nil
+}
+
+// isAText reports whether c is permitted in an Atom; this is `txtAText`
+func isAText(c byte) bool {
+	switch {
+	case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+/=?^_`{|}~-", c) >= 0
+}
+
+func isLetDig(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9'
+}
+
+// The quoted-string content predicates mirror the character classes used for
+// building `TxtEmailLHS`; for RFC2822 the content also includes the characters
+// matched by `txtWrapFWSRFC2822`.
+
+func isQContentRFC2822(c byte) bool {
+	return c >= 0x01 && c <= 0x7f && c != '"' && c != '\\'
+}
+
+func isQPairFollowRFC2822(c byte) bool {
+	return c >= 0x01 && c <= 0x7f && c != '\n' && c != '\r'
+}
+
+func isQContentRFC5321(c byte) bool {
+	return c >= 0x20 && c <= 0x7e && c != '"' && c != '\\'
+}
+
+func isQPairFollowRFC5321(c byte) bool {
+	return c >= 0x20 && c <= 0x7e
+}
@@ -0,0 +1,86 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	for _, item := range []struct {
+		text   string
+		expect Address
+	}{
+		{`john@example.org`, Address{LocalPart: "john", Domain: "example.org"}},
+		{`john.doe@list.example.org`, Address{LocalPart: "john.doe", Domain: "list.example.org"}},
+		{`"a@b"@example.org`, Address{LocalPart: "a@b", Domain: "example.org", QuotedLocalPart: true}},
+		{`"john doe"@example.org`, Address{LocalPart: "john doe", Domain: "example.org", QuotedLocalPart: true}},
+		{`"a\"b\\c"@example.org`, Address{LocalPart: `a"b\c`, Domain: "example.org", QuotedLocalPart: true}},
+		{`""@example.org`, Address{LocalPart: "", Domain: "example.org", QuotedLocalPart: true}},
+		{`john@[192.0.2.1]`, Address{LocalPart: "john", Domain: "[192.0.2.1]", AddressLiteral: true}},
+		{`john@[IPv6:2001:db8::42]`, Address{LocalPart: "john", Domain: "[IPv6:2001:db8::42]", AddressLiteral: true}},
+		{`john@[ipv6:2001:db8::42]`, Address{LocalPart: "john", Domain: "[ipv6:2001:db8::42]", AddressLiteral: true}},
+		{`deliver@xn--4bi.example`, Address{LocalPart: "deliver", Domain: "xn--4bi.example"}},
+	} {
+		got, err := ParseAddress(item.text)
+		if err != nil {
+			t.Errorf("ParseAddress(%q) failed: %v", item.text, err)
+			continue
+		}
+		if got != item.expect {
+			t.Errorf("ParseAddress(%q) gave %#v, expected %#v", item.text, got, item.expect)
+		}
+		if s := got.String(); s != item.text {
+			t.Errorf("ParseAddress(%q).String() gave %q", item.text, s)
+		}
+	}
+}
+
+func TestParseAddressErrors(t *testing.T) {
+	for _, item := range []struct {
+		text       string
+		production string
+		offset     int
+	}{
+		{``, "Local-part", 0},
+		{`john`, "Mailbox", 4},
+		{`john..doe@example.org`, "Atom", 5},
+		{`.john@example.org`, "Atom", 0},
+		{`john.@example.org`, "Atom", 5},
+		{`john doe@example.org`, "Mailbox", 4},
+		{`"john doe@example.org`, "Quoted-string", 21},
+		{`"john` + "\x80" + `"@example.org`, "Quoted-string", 5},
+		{`"john\` + "\x80" + `"@example.org`, "quoted-pair", 6},
+		{`john@`, "sub-domain", 5},
+		{`john@example`, "Domain", 5},
+		{`john@example.org.`, "sub-domain", 17},
+		{`john@example-.org`, "sub-domain", 12},
+		{`john@-example.org`, "sub-domain", 5},
+		{`john@example.org>`, "Mailbox", 16},
+		{`john@[192.0.2.1`, "address-literal", 15},
+		{`john@[192.0.2.256]`, "Snum", 14},
+		{`john@[192.0.2.01]`, "Snum", 14},
+		{`john@[192.0.2]`, "IPv4-address-literal", 13},
+		{`john@[2001:db8::42]`, "General-address-literal", 6},
+		{`john@[IPv6:2001:db8::42::1]`, "IPv6-addr", 23},
+		{`john@[IPv6:2001:db8:1:2:3:4:5]`, "IPv6-full", 11},
+		{`john@[IPv6:2001:db8:1:2:3:4::5:6]`, "IPv6-comp", 11},
+		{`john@[IPv6:2001:db8::12345]`, "IPv6-hex", 21},
+		{`john@[192.0.2.1]x`, "Mailbox", 16},
+	} {
+		_, err := ParseAddress(item.text)
+		if err == nil {
+			t.Errorf("ParseAddress(%q) succeeded, expected failure", item.text)
+			continue
+		}
+		pe, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("ParseAddress(%q) gave non-ParseError %T: %v", item.text, err, err)
+			continue
+		}
+		if pe.Production != item.production || pe.Offset != item.offset {
+			t.Errorf("ParseAddress(%q) failed in %s at %d, expected %s at %d: %v",
+				item.text, pe.Production, pe.Offset, item.production, item.offset, err)
+		}
+	}
+}
@@ -102,7 +102,27 @@ var (
 	IPNetblockUnanchored   = regexp.MustCompile(TxtIPNetblock)
 	IPNetblock             = regexp.MustCompile(start + TxtIPNetblock + end)
 
-	// these don't handle scoped addresses, but SMTP doesn't permit them
+	// these don't handle scoped addresses, but SMTP doesn't permit them; see
+	// IPv6AddressScoped for configuration files and the like
+)
+
+// The zone of a scoped address is usually an interface name (Unix) or number
+// (Windows).  RFC 6874 limits it to the URI unreserved characters; we do too,
+// but we are not in a URI so the `%` is not itself percent-encoded.  The zone
+// is optional, so any IPv6Address is also an IPv6AddressScoped.  Never use
+// this inside an email pattern.
+
+const (
+	txtIPv6ZoneID = `(?:[A-Za-z0-9._~-]+)`
+)
+
+var (
+	TxtIPv6AddressScoped = `(?:` + TxtIPv6Address + `(?:%` + txtIPv6ZoneID + `)?)`
+)
+
+var (
+	IPv6AddressScopedUnanchored = regexp.MustCompile(TxtIPv6AddressScoped)
+	IPv6AddressScoped           = regexp.MustCompile(start + TxtIPv6AddressScoped + end)
 )
 
 // See RFC 2821 (not all comments are terminology from there)
@@ -167,50 +187,107 @@ const (
 	txtWrapFWSRFC5321     = ``
 )
 
-var TxtEmailLHS string = deExtend(`
+// buildEmailLHS is parameterised on the character classes, so that the same
+// structure can be used for variant grammars.  The atext and qtext must each be
+// a single unit which can be qualified with `+`.
+func buildEmailLHS(atext, qtext, qpairFollow, wrapFWS string) string {
+	return deExtend(`
 	 # Local-part
 	 (?:
 	  (?:
 		# Dot-string
-		(?:` + txtAText + `)+ (?: \. ` + txtAText + `+)*
+		(?:` + atext + `)+ (?: \. ` + atext + `+)*
 	  ) | (?:
 		# Quoted-string
-		" (?: ` + txtWrapFWS + ` (?:
-		 (?: ` + txtQText + `+ ) |
-		 (?: \\ ` + txtQPairFollow + ` )
-		) )* ` + txtWrapFWS + ` "
+		" (?: ` + wrapFWS + ` (?:
+		 (?: ` + qtext + `+ ) |
+		 (?: \\ ` + qpairFollow + ` )
+		) )* ` + wrapFWS + ` "
 	  )
 	 )`)
+}
 
-var TxtEmailDomain string = deExtend(`
+// buildEmailDomain is parameterised on the characters which may start, be
+// within, and end a label, on whether or not to accept the
+// General-address-literal form, and on whether to use the named capture groups
+// of the Capturing forms.
+func buildEmailDomain(labelStart, labelMid, labelEnd string, generalLiteral, capture bool) string {
+	group, ipv4, ipv6 := `(?:`, TxtIPv4Address, TxtIPv6Address
+	if capture {
+		group = `(?P<domain>`
+		ipv4 = `(?P<ipv4literal>` + ipv4 + `)`
+		ipv6 = `(?P<ipv6literal>` + ipv6 + `)`
+	}
+	literals := ipv4 + ` | (?: [Ii][Pp][vV]6: ` + ipv6 + ` )`
+	if generalLiteral {
+		literals += ` | ` + txtGeneralAddressLiteral
+	}
+	return deExtend(`
 	 # Domain
-	 (?:
+	 ` + group + `
 		(?:
 		 # regular domain
-		 (?:[A-Za-z0-9] (?: [A-Za-z0-9-]*[A-Za-z0-9] )?)
-		 (?: \. [A-Za-z0-9] (?: [A-Za-z0-9-]*[A-Za-z0-9] )?)+
+		 (?:` + labelStart + ` (?: ` + labelMid + `*` + labelEnd + ` )?)
+		 (?: \. ` + labelStart + ` (?: ` + labelMid + `*` + labelEnd + ` )?)+
 		) | (?:
 			 # address-literals
 			 \[
-			   (?: ` + TxtIPv4Address + ` | (?: [Ii][Pp][vV]6: ` + TxtIPv6Address + ` ) )
-			   # NOTSUPP: General-address-literal
+			   (?: ` + literals + ` )
+			   # General-address-literal only if asked for:
 			   # G-A-L is a hook for future literal addresses
 			   # and only specifies tag:content
 			 \]
 		)
 	 )`)
+}
+
+// RFC 5321:
+//
+//	General-address-literal  = Standardized-tag ":" 1*dcontent
+//	Standardized-tag  = Ldh-str
+//	dcontent       = %d33-90 / %d94-126
+//	Ldh-str        = *( ALPHA / DIGIT / "-" ) Let-dig
+const txtGeneralAddressLiteral = `(?:[A-Za-z0-9-]*[A-Za-z0-9]:[\x21-\x5a\x5e-\x7e]+)`
+
+const (
+	txtLetDig = `[A-Za-z0-9]`
+	txtLDH    = `[A-Za-z0-9-]`
+)
+
+var TxtEmailLHS string = defaultGrammar.txtEmailLHS
+
+var TxtEmailDomain string = buildEmailDomain(txtLetDig, txtLDH, txtLetDig, false, false)
 
-var TxtEmailAddress = `(?:(?:` + TxtEmailLHS + `)@(?:` + TxtEmailDomain + `))`
+var TxtEmailAddress = defaultGrammar.txtEmailAddress
 
-var TxtEmailAddressOrUnqualified = `(?:` + TxtEmailLHS + `(?:@` + TxtEmailDomain + `)?)`
+var TxtEmailAddressOrUnqualified = defaultGrammar.txtEmailAddressOrUnqualified
 
 var (
-	EmailLHSUnanchored                  = regexp.MustCompile(TxtEmailLHS)
-	EmailLHS                            = regexp.MustCompile(start + TxtEmailLHS + end)
+	EmailLHSUnanchored                  = defaultGrammar.emailLHSUnanchored
+	EmailLHS                            = defaultGrammar.emailLHS
 	EmailDomainUnanchored               = regexp.MustCompile(TxtEmailDomain)
 	EmailDomain                         = regexp.MustCompile(start + TxtEmailDomain + end)
-	EmailAddressUnanchored              = regexp.MustCompile(TxtEmailAddress)
-	EmailAddress                        = regexp.MustCompile(start + TxtEmailAddress + end)
-	EmailAddressOrUnqualifiedUnanchored = regexp.MustCompile(TxtEmailAddressOrUnqualified)
-	EmailAddressOrUnqualified           = regexp.MustCompile(start + TxtEmailAddressOrUnqualified + end)
+	EmailAddressUnanchored              = defaultGrammar.emailAddressUnanchored
+	EmailAddress                        = defaultGrammar.emailAddress
+	EmailAddressOrUnqualifiedUnanchored = defaultGrammar.emailAddressOrUnqualifiedUnanchored
+	EmailAddressOrUnqualified           = defaultGrammar.emailAddressOrUnqualified
+)
+
+// The WithGeneralLiteral forms also accept a General-address-literal, with any
+// syntactically valid tag; use ValidateGeneralAddressLiteral to check the tag
+// against those registered.
+
+var TxtEmailDomainWithGeneralLiteral string = buildEmailDomain(txtLetDig, txtLDH, txtLetDig, true, false)
+
+var TxtEmailAddressWithGeneralLiteral = `(?:(?:` + TxtEmailLHS + `)@(?:` + TxtEmailDomainWithGeneralLiteral + `))`
+
+var TxtEmailAddressOrUnqualifiedWithGeneralLiteral = `(?:` + TxtEmailLHS + `(?:@` + TxtEmailDomainWithGeneralLiteral + `)?)`
+
+var (
+	EmailDomainWithGeneralLiteralUnanchored               = regexp.MustCompile(TxtEmailDomainWithGeneralLiteral)
+	EmailDomainWithGeneralLiteral                         = regexp.MustCompile(start + TxtEmailDomainWithGeneralLiteral + end)
+	EmailAddressWithGeneralLiteralUnanchored              = regexp.MustCompile(TxtEmailAddressWithGeneralLiteral)
+	EmailAddressWithGeneralLiteral                        = regexp.MustCompile(start + TxtEmailAddressWithGeneralLiteral + end)
+	EmailAddressOrUnqualifiedWithGeneralLiteralUnanchored = regexp.MustCompile(TxtEmailAddressOrUnqualifiedWithGeneralLiteral)
+	EmailAddressOrUnqualifiedWithGeneralLiteral           = regexp.MustCompile(start + TxtEmailAddressOrUnqualifiedWithGeneralLiteral + end)
 )
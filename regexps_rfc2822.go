@@ -4,8 +4,6 @@
 
 package emailsupport
 
-const (
-	txtQText       = txtQTextRFC2822
-	txtQPairFollow = txtQPairFollowRFC2822
-	txtWrapFWS     = txtWrapFWSRFC2822
-)
+// defaultGrammar is the Grammar used for the package-level patterns and
+// functions; see DefaultGrammar.
+var defaultGrammar = rfc2822Grammar
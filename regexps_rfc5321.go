@@ -4,8 +4,6 @@
 
 package emailsupport
 
-const (
-	txtQText       = txtQTextRFC5321
-	txtQPairFollow = txtQPairFollowRFC5321
-	txtWrapFWS     = txtWrapFWSRFC5321
-)
+// defaultGrammar is the Grammar used for the package-level patterns and
+// functions; see DefaultGrammar.
+var defaultGrammar = rfc5321Grammar
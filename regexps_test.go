@@ -31,194 +31,280 @@ func iterateBoolPatternMatch(
 	}
 }
 
+var testIPv4Octets = []boolPatternMatch{
+	{"0", true},
+	{"1", true},
+	{"9", true},
+	{"10", true},
+	{"25", true},
+	{"26", true},
+	{"99", true},
+	{"100", true},
+	{"101", true},
+	{"156", true},
+	{"199", true},
+	{"200", true},
+	{"201", true},
+	{"240", true},
+	{"245", true},
+	{"246", true},
+	{"249", true},
+	{"250", true},
+	{"251", true},
+	{"252", true},
+	{"253", true},
+	{"254", true},
+	{"255", true},
+	{"256", false},
+	{"260", false},
+	{"1.1", false},
+	{"-1", false},
+	{"-255", false},
+	{" 1 ", false},
+}
+
 func TestIPv4Octets(t *testing.T) {
-	iterateBoolPatternMatch(t, IPv4Octet, "IPv4Octet", []boolPatternMatch{
-		{"0", true},
-		{"1", true},
-		{"9", true},
-		{"10", true},
-		{"25", true},
-		{"26", true},
-		{"99", true},
-		{"100", true},
-		{"101", true},
-		{"156", true},
-		{"199", true},
-		{"200", true},
-		{"201", true},
-		{"240", true},
-		{"245", true},
-		{"246", true},
-		{"249", true},
-		{"250", true},
-		{"251", true},
-		{"252", true},
-		{"253", true},
-		{"254", true},
-		{"255", true},
-		{"256", false},
-		{"260", false},
-		{"1.1", false},
-		{"-1", false},
-		{"-255", false},
-		{" 1 ", false},
-	})
+	iterateBoolPatternMatch(t, IPv4Octet, "IPv4Octet", testIPv4Octets)
+}
+
+var testIPv4Addresses = []boolPatternMatch{
+	{"0.0.0.0", true},
+	{"255.255.255.255", true},
+	{"0.0.0.0.0", false},
+	{"192.0.2.255", true},
+	{"192.0.256.250", false},
+	{" 192.0.2.255", false},
+	{"192.0.2.255.", false},
+	{"192.168.1.2", true},
+	{"...", false},
+	{"192:0:2:2", false},
 }
 
 func TestIPv4Addresses(t *testing.T) {
-	iterateBoolPatternMatch(t, IPv4Address, "IPv4Address", []boolPatternMatch{
-		{"0.0.0.0", true},
-		{"255.255.255.255", true},
-		{"0.0.0.0.0", false},
-		{"192.0.2.255", true},
-		{"192.0.256.250", false},
-		{" 192.0.2.255", false},
-		{"192.0.2.255.", false},
-		{"192.168.1.2", true},
-		{"...", false},
-		{"192:0:2:2", false},
-	})
+	iterateBoolPatternMatch(t, IPv4Address, "IPv4Address", testIPv4Addresses)
+}
+
+var testIPv4Netblocks = []boolPatternMatch{
+	{"0.0.0.0/0", true},
+	{"127.0.0.0/8", true},
+	{"192.0.2.0/24", true},
+	{"192.0.2.0/30", true},
+	{"192.0.2.0/31", true},
+	{"192.0.2.0/32", true},
+	{"192.0.2.0/33", false},
+	{"192.0.2.0/300", false},
+	{"192.0.2.0", false},
+	{"192.0.2.0/30 ", false},
+	{"192.0.2.0/30/", false},
+	{"192.0.2.0/30.", false},
 }
 
 func TestIPv4Netblocks(t *testing.T) {
-	iterateBoolPatternMatch(t, IPv4Netblock, "IPv4Netblock", []boolPatternMatch{
-		{"0.0.0.0/0", true},
-		{"127.0.0.0/8", true},
-		{"192.0.2.0/24", true},
-		{"192.0.2.0/30", true},
-		{"192.0.2.0/31", true},
-		{"192.0.2.0/32", true},
-		{"192.0.2.0/33", false},
-		{"192.0.2.0/300", false},
-		{"192.0.2.0", false},
-		{"192.0.2.0/30 ", false},
-		{"192.0.2.0/30/", false},
-		{"192.0.2.0/30.", false},
-	})
+	iterateBoolPatternMatch(t, IPv4Netblock, "IPv4Netblock", testIPv4Netblocks)
 }
 
 // these are the tests from my emit_ipv6_regexp tool
+var testIPv6AddressesFromEmitTester = []boolPatternMatch{
+	{"::", true},
+	{"::1", true},
+	{"fe02::1", true},
+	{"::ffff:192.0.2.1", true},
+	{"2001:DB8::42", true},
+	{"2001:db8::42", true},
+	{"2001:DB8:1234:5678:90ab:cdef:0123:4567", true},
+	{"2001:DB8:1234:5678:90ab:cdef:0123::", true},
+	{"2001:DB8:1234:5678:90ab:cdef::0123", true},
+	{"2001:DB8:1234:5678:90ab:cdef:192.0.2.1", true},
+	{"2001:DB8:1234:5678:90ab:cdef:192.0.2.1", true},
+	{"127.0.0.1", false},
+	{"", false},
+	{" ", false},
+	{"192.0.2.1", false},
+	{"2001", false},
+	{"2001:DB8", false},
+	{"2001:DB8:", false},
+	{"2001:DB8::42::1", false},
+	{"2001:DB8:1234:5678:90ab:cdef:g123:4567", false},
+	{"2001:DB8:1234:5678:90ab:cdef:0123:4567:89", false},
+	{"2001:DB8:1234:5678:90ab:cdef:0123", false},
+}
+
 func TestIPv6AddressesFromEmitTester(t *testing.T) {
-	iterateBoolPatternMatch(t, IPv6Address, "IPv6Address", []boolPatternMatch{
-		{"::", true},
-		{"::1", true},
-		{"fe02::1", true},
-		{"::ffff:192.0.2.1", true},
-		{"2001:DB8::42", true},
-		{"2001:db8::42", true},
-		{"2001:DB8:1234:5678:90ab:cdef:0123:4567", true},
-		{"2001:DB8:1234:5678:90ab:cdef:0123::", true},
-		{"2001:DB8:1234:5678:90ab:cdef::0123", true},
-		{"2001:DB8:1234:5678:90ab:cdef:192.0.2.1", true},
-		{"2001:DB8:1234:5678:90ab:cdef:192.0.2.1", true},
-		{"127.0.0.1", false},
-		{"", false},
-		{" ", false},
-		{"192.0.2.1", false},
-		{"2001", false},
-		{"2001:DB8", false},
-		{"2001:DB8:", false},
-		{"2001:DB8::42::1", false},
-		{"2001:DB8:1234:5678:90ab:cdef:g123:4567", false},
-		{"2001:DB8:1234:5678:90ab:cdef:0123:4567:89", false},
-		{"2001:DB8:1234:5678:90ab:cdef:0123", false},
-	})
+	iterateBoolPatternMatch(t, IPv6Address, "IPv6Address", testIPv6AddressesFromEmitTester)
+}
+
+var testIPv6AddressesScoped = []boolPatternMatch{
+	{"fe80::1%eth0", true},
+	{"fe80::1%en0.100", true},
+	{"fe80::1%3", true},
+	{"fe80::1%wlan_0-x~y", true},
+	{"fe80::1", true},
+	{"::ffff:192.0.2.1%eth0", true},
+	{"fe80::1%", false},
+	{"fe80::1%eth 0", false},
+	{"fe80::1%eth0%eth1", false},
+	{"fe80::1%25eth0", true},
+	{"fe80::1%eth/0", false},
+	{"%eth0", false},
+	{"192.0.2.1%eth0", false},
+	{"fe80::1::2%eth0", false},
+}
+
+func TestIPv6AddressesScoped(t *testing.T) {
+	iterateBoolPatternMatch(t, IPv6AddressScoped, "IPv6AddressScoped", testIPv6AddressesScoped)
+	iterateBoolPatternMatch(t, IPv6AddressScoped, "IPv6AddressScoped", testIPv6AddressesFromEmitTester)
+}
+
+var testIPv6Netblocks = []boolPatternMatch{
+	{"::/0", true},
+	{"fe02::/8", true},
+	{"fe02::/08", false},
+	{"fe02::/10", true},
+	{"fe02::/16", true},
+	{"2001:DB8:1234:5678::/64", true},
+	{"2001:DB8:1234:5678::/127", true},
+	{"2001:DB8:1234:5678::/128", true},
+	{"2001:DB8:1234:5678::/129", false},
 }
 
 func TestIPv6Netblocks(t *testing.T) {
-	iterateBoolPatternMatch(t, IPv6Netblock, "IPv6Netblock", []boolPatternMatch{
-		{"::/0", true},
-		{"fe02::/8", true},
-		{"fe02::/08", false},
-		{"fe02::/10", true},
-		{"fe02::/16", true},
-		{"2001:DB8:1234:5678::/64", true},
-		{"2001:DB8:1234:5678::/127", true},
-		{"2001:DB8:1234:5678::/128", true},
-		{"2001:DB8:1234:5678::/129", false},
-	})
+	iterateBoolPatternMatch(t, IPv6Netblock, "IPv6Netblock", testIPv6Netblocks)
+}
+
+var testEmailLHS = []boolPatternMatch{
+	{`john`, true},
+	{`john.doe`, true},
+	{`John.Doe`, true},
+	{`alpha-beta`, true},
+	{`john+topic`, true},
+	{`""`, true},
+	{`"john"`, true},
+	{`"john doe"`, true},
+	{`a~` + "`" + `*&^%$#!_-={|}'/?b`, true},
+	{`#`, true},
+	{`"X'); DROP TABLE domains; DROP TABLE passwords; --"`, true},
+	{`"<script>alert('Boo!')</script>"`, true},
+	{`john doe`, false},
+	{`"john "`, true},
+	{`" john"`, true},
+	{`" john "`, true},
+	{`john `, false},
+	{` john`, false},
+	{` john `, false},
 }
 
 func TestEmailLHS(t *testing.T) {
-	iterateBoolPatternMatch(t, EmailLHS, "EmailLHS", []boolPatternMatch{
-		{`john`, true},
-		{`john.doe`, true},
-		{`John.Doe`, true},
-		{`alpha-beta`, true},
-		{`john+topic`, true},
-		{`""`, true},
-		{`"john"`, true},
-		{`"john doe"`, true},
-		{`a~` + "`" + `*&^%$#!_-={|}'/?b`, true},
-		{`#`, true},
-		{`"X'); DROP TABLE domains; DROP TABLE passwords; --"`, true},
-		{`"<script>alert('Boo!')</script>"`, true},
-		{`john doe`, false},
-		{`"john "`, true},
-		{`" john"`, true},
-		{`" john "`, true},
-		{`john `, false},
-		{` john`, false},
-		{` john `, false},
-	})
+	iterateBoolPatternMatch(t, EmailLHS, "EmailLHS", testEmailLHS)
+}
+
+var testEmailDomain = []boolPatternMatch{
+	{"example.org", true},
+	{"example.org.", false},
+	{".org", false},
+	{"a-b.example", true},
+	{"a--b.example", true},    // not valid to _register_ as a domain, but valid in SMTP grammar
+	{"xn--4bi.example", true}, // xn--4bi = ✉ (ENVELOPE); xn-- being why -- is valid in a domain
+	{"a-b", false},
+	{"xn--4bi", false},
+	{"", false},
+	{".", false},
+	{"192.0.2.1", true},   // is within a TLD 1, not for routing to an IP address
+	{"[192.0.2.1]", true}, // routing to an IP address
+	{"2001:db8::42", false},
+	{"[2001:db8::42]", false},
+	{"[ipv6:2001:db8::42]", true},
+	{"[IPv6:2001:db8::42]", true},
+	{"[IPv6:fe80::1%eth0]", false},
 }
 
 func TestEmailDomain(t *testing.T) {
-	iterateBoolPatternMatch(t, EmailDomain, "EmailDomain", []boolPatternMatch{
-		{"example.org", true},
-		{"example.org.", false},
-		{".org", false},
-		{"a-b.example", true},
-		{"a--b.example", true},    // not valid to _register_ as a domain, but valid in SMTP grammar
-		{"xn--4bi.example", true}, // xn--4bi = ✉ (ENVELOPE); xn-- being why -- is valid in a domain
-		{"a-b", false},
-		{"xn--4bi", false},
-		{"", false},
-		{".", false},
-		{"192.0.2.1", true},   // is within a TLD 1, not for routing to an IP address
-		{"[192.0.2.1]", true}, // routing to an IP address
-		{"2001:db8::42", false},
-		{"[2001:db8::42]", false},
-		{"[ipv6:2001:db8::42]", true},
-		{"[IPv6:2001:db8::42]", true},
-	})
+	iterateBoolPatternMatch(t, EmailDomain, "EmailDomain", testEmailDomain)
+}
+
+var testEmailAddress = []boolPatternMatch{
+	{`john@example.org`, true},
+	{`john.doe@example.org`, true},
+	{`sample-list@list.example.org`, true},
+	{`john+foo@example.org`, true},
+	{`<john.doe@example.org>`, false},
+	{`john@our-subdomain`, false},
+	{`john@our-subdomain.`, false},
+	{`john@our-subdomain.example`, true},
+	{`deliver@xn--4bi.example`, true},
+	{`john@[IPv6:2001:db8::42]`, true},
+	{`john@[192.0.2.1]`, true},
+	{`"john.doe"@example.org`, true},
+	{`"john doe"@example.org`, true},
+	{`"john doe@example.org`, false},
+	{`" john doe"@example.org`, true},
+	{`""@example.org`, true},
+
+	// in the next two, s/example/spodhuis/ to get a real address, by explicit configuration not catchall
+	{"\"a~`*&^$#_-={}'?b\"@example.org", true},
+	{`"X'); DROP TABLE domains; DROP TABLE passwords; --"@example.org`, true},
 }
 
 func TestEmailAddress(t *testing.T) {
-	iterateBoolPatternMatch(t, EmailAddress, "EmailAddress", []boolPatternMatch{
-		{`john@example.org`, true},
-		{`john.doe@example.org`, true},
-		{`sample-list@list.example.org`, true},
-		{`john+foo@example.org`, true},
-		{`<john.doe@example.org>`, false},
-		{`john@our-subdomain`, false},
-		{`john@our-subdomain.`, false},
-		{`john@our-subdomain.example`, true},
-		{`deliver@xn--4bi.example`, true},
-		{`john@[IPv6:2001:db8::42]`, true},
-		{`john@[192.0.2.1]`, true},
-		{`"john.doe"@example.org`, true},
-		{`"john doe"@example.org`, true},
-		{`"john doe@example.org`, false},
-		{`" john doe"@example.org`, true},
-		{`""@example.org`, true},
-
-		// in the next two, s/example/spodhuis/ to get a real address, by explicit configuration not catchall
-		{"\"a~`*&^$#_-={}'?b\"@example.org", true},
-		{`"X'); DROP TABLE domains; DROP TABLE passwords; --"@example.org`, true},
-	})
+	iterateBoolPatternMatch(t, EmailAddress, "EmailAddress", testEmailAddress)
+}
+
+var testEmailAddressOrUnqualified = []boolPatternMatch{
+	{`john`, true},
+	{`john@example.org`, true},
+	{`john:`, false},
+	{`"john:"`, true},
+	{`"john:"@example.org`, true},
+	{`#`, true},  // beware using for a comment
+	{`;`, false}, // better comment character
+	{`# foo`, false},
+	{`"# foo"`, true},
 }
 
 func TestEmailAddressOrUnqualified(t *testing.T) {
-	iterateBoolPatternMatch(t, EmailAddressOrUnqualified, "EmailAddressOrUnqualified", []boolPatternMatch{
-		{`john`, true},
-		{`john@example.org`, true},
-		{`john:`, false},
-		{`"john:"`, true},
-		{`"john:"@example.org`, true},
-		{`#`, true},  // beware using for a comment
-		{`;`, false}, // better comment character
-		{`# foo`, false},
-		{`"# foo"`, true},
+	iterateBoolPatternMatch(t, EmailAddressOrUnqualified, "EmailAddressOrUnqualified", testEmailAddressOrUnqualified)
+}
+
+func TestEmailDomainWithGeneralLiteral(t *testing.T) {
+	list := make([]boolPatternMatch, 0, len(testEmailDomain))
+	for _, item := range testEmailDomain {
+		switch item.text {
+		case "[2001:db8::42]":
+			// "2001" is a syntactically valid Standardized-tag
+			item.shouldMatch = true
+		case "[IPv6:fe80::1%eth0]":
+			// the pattern can not check the content for a tag, so any
+			// dcontent will do
+			item.shouldMatch = true
+		}
+		list = append(list, item)
+	}
+	iterateBoolPatternMatch(t, EmailDomainWithGeneralLiteral, "EmailDomainWithGeneralLiteral", list)
+	iterateBoolPatternMatch(t, EmailDomainWithGeneralLiteral, "EmailDomainWithGeneralLiteral", []boolPatternMatch{
+		{"[x-foo:bar]", true},
+		{"[X400:c=GB;a= ;p=Example;o=Example;s=Doe;g=John;]", false}, // space is not dcontent
+		{"[X400:c=GB;a=;p=Example;o=Example;s=Doe;g=John;]", true},
+		{"[foo-:bar]", false},
+		{"[-foo:bar]", true}, // Ldh-str may start with a hyphen
+		{"[foo:]", false},
+		{"[:bar]", false},
+		{"[foo:b[r]", false},
+		{"[foo:b\\r]", false},
+		{"[foo:b]r]", false},
+	})
+	iterateBoolPatternMatch(t, EmailDomain, "EmailDomain", []boolPatternMatch{
+		{"[x-foo:bar]", false},
+	})
+}
+
+func TestEmailAddressWithGeneralLiteral(t *testing.T) {
+	iterateBoolPatternMatch(t, EmailAddressWithGeneralLiteral, "EmailAddressWithGeneralLiteral", testEmailAddress)
+	iterateBoolPatternMatch(t, EmailAddressWithGeneralLiteral, "EmailAddressWithGeneralLiteral", []boolPatternMatch{
+		{"john@[x-foo:bar]", true},
+		{"john@[x-foo:bar", false},
+	})
+	iterateBoolPatternMatch(t, EmailAddressOrUnqualifiedWithGeneralLiteral, "EmailAddressOrUnqualifiedWithGeneralLiteral", testEmailAddressOrUnqualified)
+	iterateBoolPatternMatch(t, EmailAddressOrUnqualifiedWithGeneralLiteral, "EmailAddressOrUnqualifiedWithGeneralLiteral", []boolPatternMatch{
+		{"john@[x-foo:bar]", true},
+		{"john", true},
 	})
 }
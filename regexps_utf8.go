@@ -0,0 +1,60 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"regexp"
+)
+
+// Internationalized email, per RFC 6531 (SMTPUTF8) and RFC 6532, extends the
+// grammar with UTF-8:
+//
+//   atext       =/ UTF8-non-ascii
+//   qtextSMTP   =/ UTF8-non-ascii
+//   sub-domain  =/ U-label
+//
+// The quoted-pair is not extended.  A U-label is properly defined by IDNA2008
+// and can not be fully checked with a regexp; we permit letters, digits and
+// (other than at the start) combining marks, with hyphens only within a
+// label.  This is a superset of the LDH labels, so any address matched by
+// `EmailAddress` is also matched by `EmailAddressUTF8`.
+//
+// We exclude the C1 controls, U+0080 to U+009F, from UTF8-non-ascii.  Go's
+// regexps see each byte of invalid UTF-8 as U+FFFD, so that is excluded too,
+// lest invalid input be accepted.
+//
+// These are independent of the ASCII patterns: nothing else in the package
+// changes behaviour because these exist.
+
+const (
+	txtUTF8NonASCII = `[^\x00-\x{9f}\x{fffd}]`
+
+	txtATextUTF8 = `(?:` + txtAText + `|` + txtUTF8NonASCII + `)`
+
+	txtLetDigUTF8   = `[\p{L}\p{N}]`
+	txtLabelMidUTF8 = `[\p{L}\p{M}\p{N}-]`
+	txtLabelEndUTF8 = `[\p{L}\p{M}\p{N}]`
+)
+
+// RFC 6531 extends the RFC 5321 grammar, so the build-tag selecting the default
+// grammar does not apply here.
+var TxtEmailLHSUTF8 string = buildEmailLHS(txtATextUTF8,
+	`(?:`+txtQTextRFC5321+`|`+txtUTF8NonASCII+`)`,
+	txtQPairFollowRFC5321, txtWrapFWSRFC5321)
+
+var TxtEmailDomainUTF8 string = buildEmailDomain(txtLetDigUTF8, txtLabelMidUTF8, txtLabelEndUTF8, false, false)
+
+var TxtEmailAddressUTF8 = `(?:(?:` + TxtEmailLHSUTF8 + `)@(?:` + TxtEmailDomainUTF8 + `))`
+
+var TxtEmailAddressOrUnqualifiedUTF8 = `(?:` + TxtEmailLHSUTF8 + `(?:@` + TxtEmailDomainUTF8 + `)?)`
+
+var (
+	EmailLHSUTF8Unanchored                  = regexp.MustCompile(TxtEmailLHSUTF8)
+	EmailLHSUTF8                            = regexp.MustCompile(start + TxtEmailLHSUTF8 + end)
+	EmailDomainUTF8Unanchored               = regexp.MustCompile(TxtEmailDomainUTF8)
+	EmailDomainUTF8                         = regexp.MustCompile(start + TxtEmailDomainUTF8 + end)
+	EmailAddressUTF8Unanchored              = regexp.MustCompile(TxtEmailAddressUTF8)
+	EmailAddressUTF8                        = regexp.MustCompile(start + TxtEmailAddressUTF8 + end)
+	EmailAddressOrUnqualifiedUTF8Unanchored = regexp.MustCompile(TxtEmailAddressOrUnqualifiedUTF8)
+	EmailAddressOrUnqualifiedUTF8           = regexp.MustCompile(start + TxtEmailAddressOrUnqualifiedUTF8 + end)
+)
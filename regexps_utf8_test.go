@@ -0,0 +1,94 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+// Every ASCII test case should give the same result with the UTF-8 patterns.
+
+func TestEmailLHSUTF8(t *testing.T) {
+	iterateBoolPatternMatch(t, EmailLHSUTF8, "EmailLHSUTF8", testEmailLHS)
+	iterateBoolPatternMatch(t, EmailLHSUTF8, "EmailLHSUTF8", []boolPatternMatch{
+		{`用户`, true},
+		{`jörg`, true},
+		{`jörg.müller`, true},
+		{`"jörg müller"`, true},
+		{`jörg müller`, false},
+		{`jörg..müller`, false},
+		{`.用户`, false},
+		{"j\xf6rg", false},       // Latin-1, not UTF-8
+		{"j\xc3rg", false},       // truncated sequence
+		{"j\u0085rg", false},     // C1 control NEL
+		{"\"j\u009frg\"", false}, // C1 control APC
+		{"j\ufffdrg", false},
+	})
+	iterateBoolPatternMatch(t, EmailLHS, "EmailLHS", []boolPatternMatch{
+		{`用户`, false},
+		{`"jörg"`, false},
+	})
+}
+
+func TestEmailDomainUTF8(t *testing.T) {
+	iterateBoolPatternMatch(t, EmailDomainUTF8, "EmailDomainUTF8", testEmailDomain)
+	iterateBoolPatternMatch(t, EmailDomainUTF8, "EmailDomainUTF8", []boolPatternMatch{
+		{`例子.广告`, true},
+		{`✉.example`, false}, // ENVELOPE is a symbol, not a letter; not valid IDNA2008
+		{`bücher.example`, true},
+		{"bu\u0308cher.example", true}, // combining diaeresis
+		{"\u0308bucher.example", false},
+		{`пример.испытание`, true},
+		{`пример-.испытание`, false},
+		{`пример`, false},
+		{`例子.广告.`, false},
+	})
+	iterateBoolPatternMatch(t, EmailDomain, "EmailDomain", []boolPatternMatch{
+		{`例子.广告`, false},
+	})
+}
+
+func TestEmailAddressUTF8(t *testing.T) {
+	iterateBoolPatternMatch(t, EmailAddressUTF8, "EmailAddressUTF8", testEmailAddress)
+	iterateBoolPatternMatch(t, EmailAddressUTF8, "EmailAddressUTF8", []boolPatternMatch{
+		{`用户@例子.广告`, true},
+		{`jörg@bücher.example`, true},
+		{`john@bücher.example`, true},
+		{`jörg@example.org`, true},
+		{`"jörg müller"@example.org`, true},
+		{`jörg@[192.0.2.1]`, true},
+		{`用户@例子`, false},
+		{`<用户@例子.广告>`, false},
+		{`用户 @例子.广告`, false},
+		{"j\xf6rg@example.org", false},
+		{"j\u0080rg@example.org", false},
+		{"jörg@b\xfccher.example", false},
+	})
+	iterateBoolPatternMatch(t, EmailAddress, "EmailAddress", []boolPatternMatch{
+		{`用户@例子.广告`, false},
+		{`jörg@example.org`, false},
+		{`john@bücher.example`, false},
+	})
+}
+
+func TestEmailAddressOrUnqualifiedUTF8(t *testing.T) {
+	iterateBoolPatternMatch(t, EmailAddressOrUnqualifiedUTF8, "EmailAddressOrUnqualifiedUTF8", testEmailAddressOrUnqualified)
+	iterateBoolPatternMatch(t, EmailAddressOrUnqualifiedUTF8, "EmailAddressOrUnqualifiedUTF8", []boolPatternMatch{
+		{`用户`, true},
+		{`用户@例子.广告`, true},
+		{`用户:`, false},
+	})
+}
+
+// The UTF-8 patterns extend RFC5321, whichever grammar is the default.
+func TestEmailAddressUTF8IsRFC5321(t *testing.T) {
+	cases := []boolPatternMatch{
+		{`"a b"@example.org`, true},
+		{"\"a\tb\"@example.org", false},
+		{"\"a\x01b\"@example.org", false},
+		{"\"a\\\x01b\"@example.org", false},
+		{"\"a\r\n b\"@example.org", false},
+	}
+	iterateBoolPatternMatch(t, EmailAddressUTF8, "EmailAddressUTF8", cases)
+	iterateBoolPatternMatch(t, RFC5321().EmailAddress(), "RFC5321 EmailAddress", cases)
+}
@@ -0,0 +1,94 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"strings"
+)
+
+// SubAddress is a local part split into a base and a detail, such as
+// `john+topic` split into `john` and `topic` by the delimiter `+`.  If no
+// delimiter was found, then Delimiter and Detail are empty.  All parts are
+// unquoted, as is Address.LocalPart.
+type SubAddress struct {
+	Base      string
+	Delimiter string
+	Detail    string
+}
+
+// String returns the local part which the SubAddress represents, unquoted.
+func (s SubAddress) String() string {
+	return s.Base + s.Delimiter + s.Detail
+}
+
+// RecipientDelimiters are the strings which may separate the base of a local
+// part from a detail.  Usually each is a single character, but a delimiter
+// may be longer.
+type RecipientDelimiters []string
+
+// ParseRecipientDelimiters takes a Postfix-style `recipient_delimiter`
+// setting, in which each character is a separate delimiter.
+func ParseRecipientDelimiters(postfix string) RecipientDelimiters {
+	var d RecipientDelimiters
+	for _, r := range postfix {
+		d = append(d, string(r))
+	}
+	return d
+}
+
+// Split splits an unquoted local part at the first place where any of the
+// delimiters appears; if two delimiters match at the same place then the
+// longer is used.  As with Postfix, a local part is not split if that would
+// leave the base empty, so `+topic` has no detail.
+func (d RecipientDelimiters) Split(localPart string) SubAddress {
+	best, bestDelim := -1, ""
+	if localPart == "" {
+		return SubAddress{}
+	}
+	for _, delim := range d {
+		if delim == "" {
+			continue
+		}
+		// searching from the second octet keeps the base non-empty
+		i := strings.Index(localPart[1:], delim)
+		if i < 0 {
+			continue
+		}
+		i++
+		if best < 0 || i < best || (i == best && len(delim) > len(bestDelim)) {
+			best, bestDelim = i, delim
+		}
+	}
+	if best < 0 {
+		return SubAddress{Base: localPart}
+	}
+	return SubAddress{
+		Base:      localPart[:best],
+		Delimiter: bestDelim,
+		Detail:    localPart[best+len(bestDelim):],
+	}
+}
+
+// Compose builds a SubAddress from a base and detail, using the first
+// delimiter; if detail is empty, or there are no delimiters, then the result
+// has just the base.
+func (d RecipientDelimiters) Compose(base, detail string) SubAddress {
+	if detail == "" || len(d) == 0 {
+		return SubAddress{Base: base}
+	}
+	return SubAddress{Base: base, Delimiter: d[0], Detail: detail}
+}
+
+// SplitSubAddress splits the local part of the address; because the local
+// part is held unquoted, this works for quoted local parts too.
+func (a Address) SplitSubAddress(d RecipientDelimiters) SubAddress {
+	return d.Split(a.LocalPart)
+}
+
+// WithSubAddress returns a copy of the address with the local part replaced
+// by that of the SubAddress, quoted only if that is needed.
+func (a Address) WithSubAddress(s SubAddress) Address {
+	a.LocalPart = s.String()
+	a.QuotedLocalPart = !isDotString(a.LocalPart)
+	return a
+}
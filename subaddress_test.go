@@ -0,0 +1,74 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"testing"
+)
+
+func TestRecipientDelimitersSplit(t *testing.T) {
+	for _, item := range []struct {
+		delims RecipientDelimiters
+		in     string
+		base   string
+		delim  string
+		detail string
+	}{
+		{RecipientDelimiters{"+"}, "john+topic", "john", "+", "topic"},
+		{RecipientDelimiters{"+"}, "john", "john", "", ""},
+		{RecipientDelimiters{"+"}, "john+", "john", "+", ""},
+		{RecipientDelimiters{"+"}, "john+a+b", "john", "+", "a+b"},
+		{RecipientDelimiters{"+"}, "+topic", "+topic", "", ""},
+		{RecipientDelimiters{"+"}, "", "", "", ""},
+		{ParseRecipientDelimiters("+-"), "john-doe+topic", "john", "-", "doe+topic"},
+		{ParseRecipientDelimiters("+-"), "john+doe-topic", "john", "+", "doe-topic"},
+		{RecipientDelimiters{"-", "--"}, "john--topic", "john", "--", "topic"},
+		{RecipientDelimiters{"--"}, "john-doe--topic", "john-doe", "--", "topic"},
+		{RecipientDelimiters{""}, "john+topic", "john+topic", "", ""},
+		{nil, "john+topic", "john+topic", "", ""},
+	} {
+		got := item.delims.Split(item.in)
+		if got.Base != item.base || got.Delimiter != item.delim || got.Detail != item.detail {
+			t.Errorf("%q.Split(%q) gave %#v, expected base %q delimiter %q detail %q",
+				item.delims, item.in, got, item.base, item.delim, item.detail)
+		}
+		if got.String() != item.in {
+			t.Errorf("%q.Split(%q).String() gave %q", item.delims, item.in, got.String())
+		}
+	}
+}
+
+func TestSubAddressQuoted(t *testing.T) {
+	delims := ParseRecipientDelimiters("+")
+	for _, item := range []struct {
+		in     string
+		base   string
+		detail string
+		bare   string
+		joined string
+	}{
+		{`john+topic@example.org`, "john", "topic", `john@example.org`, `john+other@example.org`},
+		{`"john doe+topic"@example.org`, "john doe", "topic", `"john doe"@example.org`, `"john doe+other"@example.org`},
+		{`"john+a b"@example.org`, "john", "a b", `john@example.org`, `john+other@example.org`},
+		{`"john+topic"@example.org`, "john", "topic", `john@example.org`, `john+other@example.org`},
+		{`"john.+x"@example.org`, "john.", "x", `"john."@example.org`, `john.+other@example.org`},
+	} {
+		a, err := ParseAddress(item.in)
+		if err != nil {
+			t.Fatalf("ParseAddress(%q) failed: %v", item.in, err)
+		}
+		s := a.SplitSubAddress(delims)
+		if s.Base != item.base || s.Detail != item.detail {
+			t.Errorf("SplitSubAddress(%q) gave %#v, expected %q and %q", item.in, s, item.base, item.detail)
+		}
+		if got := a.WithSubAddress(delims.Compose(s.Base, "")).String(); got != item.bare {
+			t.Errorf("%q without detail gave %q, expected %q", item.in, got, item.bare)
+		}
+		if got := a.WithSubAddress(delims.Compose(s.Base, "other")).String(); got != item.joined {
+			t.Errorf("%q with new detail gave %q, expected %q", item.in, got, item.joined)
+		}
+		if got := a.WithSubAddress(s); !got.Equal(a, false) {
+			t.Errorf("%q split and rejoined gave %q", item.in, got)
+		}
+	}
+}
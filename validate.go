@@ -0,0 +1,177 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+// The validators here use the same hand-written recursive-descent parser as
+// ParseAddress and do not use any regular expressions.  They accept exactly
+// the same grammar as the corresponding regexps (this is checked by the tests)
+// but can report why some text was rejected, and run in time linear in the
+// length of the input.
+//
+// Each ValidateFoo returns nil if the text would be matched by the anchored
+// `Foo` regexp, or else a *ParseError.
+
+// ValidateEmailAddress checks text against the `EmailAddress` grammar.
+func ValidateEmailAddress(text string) error {
+	_, err := ParseAddress(text)
+	return err
+}
+
+// ValidateEmailLHS checks text against the `EmailLHS` grammar.
+func ValidateEmailLHS(text string) error {
+	return defaultGrammar.ValidateEmailLHS(text)
+}
+
+// ValidateEmailDomain checks text against the `EmailDomain` grammar.
+func ValidateEmailDomain(text string) error {
+	p := defaultGrammar.newParser(text)
+	if _, _, err := p.domain(); err != nil {
+		return err
+	}
+	return p.finished("Domain")
+}
+
+// ValidateEmailAddressOrUnqualified checks text against the
+// `EmailAddressOrUnqualified` grammar.
+func ValidateEmailAddressOrUnqualified(text string) error {
+	return defaultGrammar.ValidateEmailAddressOrUnqualified(text)
+}
+
+// ValidateIPv4Address checks text against the `IPv4Address` grammar.
+func ValidateIPv4Address(text string) error {
+	p := defaultGrammar.newParser(text)
+	if err := p.ipv4Address(); err != nil {
+		return err
+	}
+	return p.finished("IPv4-address-literal")
+}
+
+// ValidateIPv6Address checks text against the `IPv6Address` grammar.
+func ValidateIPv6Address(text string) error {
+	p := defaultGrammar.newParser(text)
+	if err := p.ipv6Address(); err != nil {
+		return err
+	}
+	return p.finished("IPv6-addr")
+}
+
+// finished returns an error unless all input has been consumed
+func (p *addrParser) finished(production string) error {
+	if p.pos == len(p.in) {
+		return nil
+	}
+	return p.fail(production, p.pos, "unexpected "+p.describe(p.pos)+" after "+production)
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// snum is a decimal number 0 to 255, without leading zeroes; this is stricter
+// than RFC5321 but matches `TxtIPv4Octet`.
+func (p *addrParser) snum() error {
+	start := p.pos
+	value := 0
+	for c, ok := p.peek(); ok && isDigit(c); c, ok = p.peek() {
+		value = value*10 + int(c-'0')
+		p.pos++
+		if p.pos-start > 3 {
+			return p.fail("Snum", start, "too many digits")
+		}
+	}
+	switch {
+	case p.pos == start:
+		return p.fail("Snum", start, "expected digit, found "+p.describe(start))
+	case p.pos-start > 1 && p.in[start] == '0':
+		return p.fail("Snum", start, "leading zero not permitted")
+	case value > 255:
+		return p.fail("Snum", start, "value exceeds 255")
+	}
+	return nil
+}
+
+func (p *addrParser) ipv4Address() error {
+	if err := p.snum(); err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		if !p.consume('.') {
+			return p.fail("IPv4-address-literal", p.pos, "expected '.', found "+p.describe(p.pos))
+		}
+		if err := p.snum(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ipv6Address handles all the RFC3986 forms which are in `TxtIPv6Address`.
+// Rather than try each alternative in turn, we count 16-bit groups (with a
+// trailing IPv4 address being two groups) and check the total: there must be
+// eight groups, or at most seven if "::" was used to elide some.
+func (p *addrParser) ipv6Address() error {
+	start := p.pos
+	groups := 0
+	elided := false
+	if p.hasPrefix("::") {
+		p.pos += 2
+		elided = true
+	}
+	if elided && !p.startsH16() {
+		// just "::" by itself
+		return nil
+	}
+	for {
+		hexEnd := p.pos
+		for hexEnd < len(p.in) && isHexDigit(p.in[hexEnd]) {
+			hexEnd++
+		}
+		if hexEnd < len(p.in) && p.in[hexEnd] == '.' {
+			if err := p.ipv4Address(); err != nil {
+				return err
+			}
+			groups += 2
+			break
+		}
+		switch n := hexEnd - p.pos; {
+		case n == 0:
+			return p.fail("IPv6-hex", p.pos, "expected hex digit, found "+p.describe(p.pos))
+		case n > 4:
+			return p.fail("IPv6-hex", p.pos, "more than four hex digits")
+		}
+		p.pos = hexEnd
+		groups++
+		if p.hasPrefix("::") {
+			if elided {
+				return p.fail("IPv6-addr", p.pos, `"::" may only appear once`)
+			}
+			elided = true
+			p.pos += 2
+			if !p.startsH16() {
+				break
+			}
+			continue
+		}
+		if !p.consume(':') {
+			break
+		}
+	}
+	switch {
+	case elided && groups > 7:
+		return p.fail("IPv6-comp", start, "too many groups with \"::\"")
+	case !elided && groups != 8:
+		return p.fail("IPv6-full", start, "need eight groups without \"::\"")
+	}
+	return nil
+}
+
+func (p *addrParser) hasPrefix(prefix string) bool {
+	return len(p.in)-p.pos >= len(prefix) && p.in[p.pos:p.pos+len(prefix)] == prefix
+}
+
+func (p *addrParser) startsH16() bool {
+	c, ok := p.peek()
+	return ok && isHexDigit(c)
+}
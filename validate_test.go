@@ -0,0 +1,117 @@
+// © Phil Pennock 2026.  See LICENSE file for licensing.
+
+package emailsupport
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+)
+
+type differentialPair struct {
+	label    string
+	pattern  *regexp.Regexp
+	validate func(string) error
+}
+
+var differentialPairs = []differentialPair{
+	{"EmailAddress", EmailAddress, ValidateEmailAddress},
+	{"EmailLHS", EmailLHS, ValidateEmailLHS},
+	{"EmailDomain", EmailDomain, ValidateEmailDomain},
+	{"EmailAddressOrUnqualified", EmailAddressOrUnqualified, ValidateEmailAddressOrUnqualified},
+	{"IPv4Address", IPv4Address, ValidateIPv4Address},
+	{"IPv6Address", IPv6Address, ValidateIPv6Address},
+	{"IPv6AddressScoped", IPv6AddressScoped, ValidateIPv6AddressScoped},
+	{"IPv4Netblock", IPv4Netblock, ValidateIPv4Netblock},
+	{"IPv6Netblock", IPv6Netblock, ValidateIPv6Netblock},
+	{"IPNetblock", IPNetblock, ValidateIPNetblock},
+	{"RFC2822.EmailAddress", RFC2822().EmailAddress(), RFC2822().ValidateEmailAddress},
+	{"RFC2822.EmailLHS", RFC2822().EmailLHS(), RFC2822().ValidateEmailLHS},
+	{"RFC2822.EmailAddressOrUnqualified", RFC2822().EmailAddressOrUnqualified(), RFC2822().ValidateEmailAddressOrUnqualified},
+	{"RFC5321.EmailAddress", RFC5321().EmailAddress(), RFC5321().ValidateEmailAddress},
+	{"RFC5321.EmailLHS", RFC5321().EmailLHS(), RFC5321().ValidateEmailLHS},
+	{"RFC5321.EmailAddressOrUnqualified", RFC5321().EmailAddressOrUnqualified(), RFC5321().ValidateEmailAddressOrUnqualified},
+}
+
+// differentialCorpus is every input from the regexp tests; each validator is
+// checked against each input, not just those written for its own regexp.
+func differentialCorpus() []string {
+	var corpus []string
+	for _, list := range [][]boolPatternMatch{
+		testIPv4Octets,
+		testIPv4Addresses,
+		testIPv4Netblocks,
+		testIPv6AddressesFromEmitTester,
+		testIPv6AddressesScoped,
+		testIPv6Netblocks,
+		testEmailLHS,
+		testEmailDomain,
+		testEmailAddress,
+		testEmailAddressOrUnqualified,
+	} {
+		for _, item := range list {
+			corpus = append(corpus, item.text)
+		}
+	}
+	return corpus
+}
+
+func checkDifferential(t *testing.T, text string) {
+	t.Helper()
+	for _, pair := range differentialPairs {
+		reOK := pair.pattern.MatchString(text)
+		err := pair.validate(text)
+		if reOK != (err == nil) {
+			t.Errorf("%s disagreement on %q: regexp says %v, validator says %v", pair.label, text, reOK, err)
+		}
+		if err != nil {
+			if _, ok := err.(*ParseError); !ok {
+				t.Errorf("%s on %q gave non-ParseError %T", pair.label, text, err)
+			}
+		}
+	}
+}
+
+func TestValidatorsAgreeWithRegexps(t *testing.T) {
+	for _, text := range differentialCorpus() {
+		checkDifferential(t, text)
+	}
+}
+
+func TestValidatorsAgreeWithRegexpsMutated(t *testing.T) {
+	// Fixed seed, so that any failure is reproducible; the fuzzer below is
+	// for open-ended exploration.
+	rng := rand.New(rand.NewSource(5321))
+	corpus := differentialCorpus()
+	const alphabet = "aZ09.@:[]\"\\ -_+#/%!\x01\x7f\x80fF:::..255IPv6"
+	for i := 0; i < 20000; i++ {
+		b := []byte(corpus[rng.Intn(len(corpus))])
+		for n := rng.Intn(4); n >= 0; n-- {
+			pos := 0
+			if len(b) > 0 {
+				pos = rng.Intn(len(b) + 1)
+			}
+			c := alphabet[rng.Intn(len(alphabet))]
+			switch rng.Intn(3) {
+			case 0:
+				b = append(b[:pos], append([]byte{c}, b[pos:]...)...)
+			case 1:
+				if pos < len(b) {
+					b = append(b[:pos], b[pos+1:]...)
+				}
+			case 2:
+				if pos < len(b) {
+					b[pos] = c
+				}
+			}
+		}
+		checkDifferential(t, string(b))
+	}
+}
+
+func FuzzValidatorsAgreeWithRegexps(f *testing.F) {
+	for _, text := range differentialCorpus() {
+		f.Add(text)
+	}
+	f.Fuzz(checkDifferential)
+}